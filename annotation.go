@@ -0,0 +1,134 @@
+package simplegen
+
+import (
+	"errors"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ErrSkip can be returned by AnnotationFunc to skip annotation without error.
+var ErrSkip = errors.New("skip annotation")
+
+// Annotation describes declaration annotated with magic comment.
+type Annotation struct {
+	// Generator is a generator name from magic comment.
+	Generator GeneratorName
+	// Directive is a parsed magic comment.
+	Directive *Directive
+	// Comment is a magic comment itself.
+	Comment *ast.Comment
+	// Args contains parsed arguments of magic comment, see TemplateGenerator.Args.
+	Args Arguments
+
+	// Pos is a position of annotated declaration.
+	Pos token.Position
+	// Doc is a doc comment of annotated declaration without magic comments, nil if nothing left.
+	Doc *ast.CommentGroup
+	// File is a file with annotated declaration.
+	File *ast.File
+	// Node is an annotated declaration: *ast.TypeSpec, *ast.FuncDecl or *ast.ValueSpec.
+	Node ast.Node
+
+	// Object is declared by Node: *types.TypeName, *types.Func, *types.Const or *types.Var.
+	Object types.Object
+	// Named is a type of Object if it's a named type, nil otherwise.
+	Named *types.Named
+	// Package where magic comment was found.
+	Package *packages.Package
+
+	sg *SimpleGenerator
+	// imports are required by annotation helpers like TypeString
+	imports []Import
+}
+
+// Name returns name of annotated declaration.
+func (a *Annotation) Name() string {
+	if a.Object == nil {
+		return ""
+	}
+	return a.Object.Name()
+}
+
+// AnnotationFunc for generating template data from annotated declarations.
+// Return ErrSkip to ignore annotation.
+type AnnotationFunc func(sg *SimpleGenerator, a *Annotation) (templateData SpecData, imports []Import, err error)
+
+// Annotated adapts GeneratorFunc to AnnotationFunc.
+// Annotations of declarations other than types are skipped.
+func (f GeneratorFunc) Annotated() AnnotationFunc {
+	return func(sg *SimpleGenerator, a *Annotation) (SpecData, []Import, error) {
+		typeSpec, ok := a.Node.(*ast.TypeSpec)
+		if !ok {
+			return nil, nil, ErrSkip
+		}
+		templateData, imports, err := f(sg, a.Package, typeSpec, a.Comment)
+		return templateData, Imports(imports...), err
+	}
+}
+
+func newAnnotation(
+	sg *SimpleGenerator,
+	pkg *packages.Package,
+	file *ast.File,
+	node ast.Node,
+	doc *ast.CommentGroup,
+	obj types.Object,
+	comment *ast.Comment,
+	directive *Directive,
+) *Annotation {
+	pos := node.Pos()
+	if obj != nil {
+		pos = obj.Pos()
+	}
+
+	var named *types.Named
+	if typeName, ok := obj.(*types.TypeName); ok {
+		named, _ = typeName.Type().(*types.Named)
+	}
+
+	return &Annotation{
+		Generator: directive.Generator,
+		Directive: directive,
+		Comment:   comment,
+		Pos:       sg.fset.Position(pos),
+		Doc:       stripDirectives(doc),
+		File:      file,
+		Node:      node,
+		Object:    obj,
+		Named:     named,
+		Package:   pkg,
+		sg:        sg,
+	}
+}
+
+// annotationError wraps error of annotation processing with its position.
+// Errors already wrapped by generator are returned as is.
+func annotationError(a *Annotation, err error) error {
+	var annotationErr *AnnotationError
+	if errors.As(err, &annotationErr) {
+		return err
+	}
+	return &AnnotationError{
+		Generator: a.Generator,
+		Pos:       a.Pos,
+		TypeName:  a.Name(),
+		Err:       err,
+	}
+}
+
+// stripDirectives returns copy of doc without magic comments.
+func stripDirectives(doc *ast.CommentGroup) *ast.CommentGroup {
+	var list []*ast.Comment
+	for _, comment := range doc.List {
+		if _, ok := ParseDirective(comment.Text); !ok {
+			list = append(list, comment)
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+	return &ast.CommentGroup{List: list}
+}
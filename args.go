@@ -0,0 +1,321 @@
+package simplegen
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ArgType is a type of generator argument value.
+type ArgType int
+
+const (
+	StringArg ArgType = iota
+	IntArg
+	BoolArg
+)
+
+func (t ArgType) String() string {
+	switch t {
+	case StringArg:
+		return "string"
+	case IntArg:
+		return "int"
+	case BoolArg:
+		return "bool"
+	default:
+		return fmt.Sprintf("ArgType(%d)", int(t))
+	}
+}
+
+// Arg describes single generator argument.
+// Magic comment can pass it in one of the forms
+//
+//	// simplegen:sort-by-keys -type *models.User -suffix=ByEmail fieldName=Email
+//
+// Values with spaces should be quoted: -doc "sorted by email" or -doc 'sorted by email'.
+// Bool arguments can be passed without value: -verbose.
+type Arg struct {
+	Name string
+	Type ArgType
+	// Default is used when argument is not passed, it should be valid value of Type.
+	Default string
+	// Required argument must be passed in magic comment.
+	Required bool
+	// Repeated argument can be passed several times, use Arguments.Strings/Ints/Bools to get all values.
+	Repeated bool
+	// Enum restricts allowed values.
+	Enum []string
+	// Usage is a help text for argument.
+	Usage string
+}
+
+func (a *Arg) validate(value string) error {
+	switch a.Type {
+	case StringArg:
+	case IntArg:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("argument %s: invalid int value %q", a.Name, value)
+		}
+	case BoolArg:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("argument %s: invalid bool value %q", a.Name, value)
+		}
+	default:
+		return fmt.Errorf("argument %s: unknown type %s", a.Name, a.Type)
+	}
+
+	if len(a.Enum) == 0 {
+		return nil
+	}
+	for _, allowed := range a.Enum {
+		if value == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("argument %s: value %q is not one of %s", a.Name, value, strings.Join(a.Enum, ", "))
+}
+
+// Arguments contains parsed and validated generator arguments.
+// Values are checked against Arg schema, so getters don't return errors.
+type Arguments struct {
+	values map[string][]string
+}
+
+// Has reports if argument was passed in magic comment or has default value.
+func (a Arguments) Has(name string) bool {
+	return len(a.values[name]) > 0
+}
+
+// String returns argument value, last one for repeated arguments.
+func (a Arguments) String(name string) string {
+	values := a.values[name]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// Int returns argument value, last one for repeated arguments.
+func (a Arguments) Int(name string) int {
+	v, _ := strconv.Atoi(a.String(name))
+	return v
+}
+
+// Bool returns argument value, last one for repeated arguments.
+func (a Arguments) Bool(name string) bool {
+	v, _ := strconv.ParseBool(a.String(name))
+	return v
+}
+
+// Strings returns all values of repeated argument.
+func (a Arguments) Strings(name string) []string {
+	return a.values[name]
+}
+
+// Ints returns all values of repeated argument.
+func (a Arguments) Ints(name string) []int {
+	values := make([]int, 0, len(a.values[name]))
+	for _, s := range a.values[name] {
+		v, _ := strconv.Atoi(s)
+		values = append(values, v)
+	}
+	return values
+}
+
+// Bools returns all values of repeated argument.
+func (a Arguments) Bools(name string) []bool {
+	values := make([]bool, 0, len(a.values[name]))
+	for _, s := range a.values[name] {
+		v, _ := strconv.ParseBool(s)
+		values = append(values, v)
+	}
+	return values
+}
+
+// ParseArgs parses raw magic comment arguments according to generator Args schema.
+func (tg TemplateGenerator) ParseArgs(raw string) (Arguments, error) {
+	tokens, err := splitArgs(raw)
+	if err != nil {
+		return Arguments{}, err
+	}
+
+	specs := make(map[string]*Arg, len(tg.Args))
+	for i := range tg.Args {
+		specs[tg.Args[i].Name] = &tg.Args[i]
+	}
+
+	values := make(map[string][]string)
+	for i := 0; i < len(tokens); i++ {
+		name, value, hasValue := strings.Cut(tokens[i], "=")
+		isFlag := strings.HasPrefix(name, "-")
+		if !isFlag && !hasValue {
+			return Arguments{}, fmt.Errorf("unexpected argument %q", tokens[i])
+		}
+		name = strings.TrimLeft(name, "-")
+
+		spec, ok := specs[name]
+		if !ok {
+			return Arguments{}, fmt.Errorf("unknown argument %q", name)
+		}
+
+		if !hasValue {
+			switch {
+			case spec.Type == BoolArg:
+				value = "true"
+			case i+1 < len(tokens):
+				i++
+				value = tokens[i]
+			default:
+				return Arguments{}, fmt.Errorf("argument %s: missing value", name)
+			}
+		}
+
+		if err = spec.validate(value); err != nil {
+			return Arguments{}, err
+		}
+		if len(values[name]) > 0 && !spec.Repeated {
+			return Arguments{}, fmt.Errorf("argument %s: passed more than once", name)
+		}
+		values[name] = append(values[name], value)
+	}
+
+	for _, spec := range tg.Args {
+		if len(values[spec.Name]) > 0 {
+			continue
+		}
+		if spec.Required {
+			return Arguments{}, fmt.Errorf("argument %s: required", spec.Name)
+		}
+		if spec.Default != "" {
+			values[spec.Name] = []string{spec.Default}
+		}
+	}
+
+	return Arguments{values: values}, nil
+}
+
+// validateArgs checks generator Args schema itself.
+func (tg TemplateGenerator) validateArgs() error {
+	seen := make(map[string]struct{}, len(tg.Args))
+	for i := range tg.Args {
+		spec := &tg.Args[i]
+		if spec.Name == "" || strings.ContainsAny(spec.Name, "= \t") || strings.HasPrefix(spec.Name, "-") {
+			return fmt.Errorf("invalid argument name %q", spec.Name)
+		}
+		if _, ok := seen[spec.Name]; ok {
+			return fmt.Errorf("argument %s: declared more than once", spec.Name)
+		}
+		seen[spec.Name] = struct{}{}
+
+		if spec.Default == "" {
+			continue
+		}
+		if err := spec.validate(spec.Default); err != nil {
+			return fmt.Errorf("default value: %w", err)
+		}
+	}
+	return nil
+}
+
+// PrintUsage writes help for every generator and its arguments.
+func (gm GeneratorsMap) PrintUsage(w io.Writer) {
+	names := make([]string, 0, len(gm))
+	for name := range gm {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		generator := gm[GeneratorName(name)]
+		fmt.Fprintf(w, "%s:%s\n", CmdKey, name)
+		if generator.Usage != "" {
+			fmt.Fprintf(w, "  %s\n", generator.Usage)
+		}
+		for _, arg := range generator.Args {
+			var extra []string
+			if arg.Required {
+				extra = append(extra, "required")
+			}
+			if arg.Repeated {
+				extra = append(extra, "repeated")
+			}
+			if arg.Default != "" {
+				extra = append(extra, fmt.Sprintf("default %q", arg.Default))
+			}
+			if len(arg.Enum) > 0 {
+				extra = append(extra, "one of: "+strings.Join(arg.Enum, ", "))
+			}
+
+			line := fmt.Sprintf("  -%s %s", arg.Name, arg.Type)
+			if len(extra) > 0 {
+				line += " (" + strings.Join(extra, "; ") + ")"
+			}
+			fmt.Fprintln(w, line)
+			if arg.Usage != "" {
+				fmt.Fprintf(w, "    \t%s\n", arg.Usage)
+			}
+		}
+	}
+}
+
+// splitArgs splits raw arguments by whitespaces respecting quotes.
+// Double-quoted values support Go escape sequences, single-quoted values are taken as is.
+func splitArgs(raw string) ([]string, error) {
+	var (
+		args  []string
+		cur   strings.Builder
+		inArg bool
+	)
+
+	for i := 0; i < len(raw); i++ {
+		c, size := utf8.DecodeRuneInString(raw[i:])
+		switch {
+		case c == '"':
+			end := i + 1
+			for ; end < len(raw) && raw[end] != '"'; end++ {
+				if raw[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(raw) {
+				return nil, fmt.Errorf("unterminated quote in %q", raw)
+			}
+			s, err := strconv.Unquote(raw[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value %s: %w", raw[i:end+1], err)
+			}
+			cur.WriteString(s)
+			inArg = true
+			i = end
+		case c == '\'':
+			end := strings.IndexByte(raw[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in %q", raw)
+			}
+			cur.WriteString(raw[i+1 : i+1+end])
+			inArg = true
+			i += end + 1
+		case unicode.IsSpace(c):
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+			i += size - 1
+		default:
+			// write whole rune, invalid UTF-8 is kept as is
+			cur.WriteString(raw[i : i+size])
+			inArg = true
+			i += size - 1
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
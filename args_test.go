@@ -0,0 +1,151 @@
+package simplegen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: nil},
+		{name: "spaces only", raw: " \t ", want: nil},
+		{name: "fields", raw: "-type *models.User  fieldName=Email", want: []string{"-type", "*models.User", "fieldName=Email"}},
+		{name: "double quotes", raw: `-doc "sorted by email"`, want: []string{"-doc", "sorted by email"}},
+		{name: "double quotes escape", raw: `-doc "say \"hi\""`, want: []string{"-doc", `say "hi"`}},
+		{name: "single quotes", raw: `-doc 'a "b" c'`, want: []string{"-doc", `a "b" c`}},
+		{name: "quoted part of arg", raw: `doc="a b"`, want: []string{"doc=a b"}},
+		{name: "empty quoted", raw: `-doc ""`, want: []string{"-doc", ""}},
+		{name: "non-ascii", raw: "-label voilà", want: []string{"-label", "voilà"}},
+		{name: "non-ascii first", raw: "-label Åse -x", want: []string{"-label", "Åse", "-x"}},
+		{name: "non-ascii space", raw: "a b　c", want: []string{"a", "b", "c"}},
+		{name: "invalid utf8 kept", raw: "a\xffb", want: []string{"a\xffb"}},
+		{name: "unterminated double quote", raw: `-doc "abc`, wantErr: true},
+		{name: "unterminated single quote", raw: `-doc 'abc`, wantErr: true},
+		{name: "invalid escape", raw: `-doc "\q"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitArgs(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitArgs(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	tg := TemplateGenerator{Args: []Arg{
+		{Name: "type", Required: true},
+		{Name: "suffix", Default: "Sorted"},
+		{Name: "limit", Type: IntArg},
+		{Name: "verbose", Type: BoolArg},
+		{Name: "tag", Repeated: true},
+		{Name: "order", Enum: []string{"asc", "desc"}},
+	}}
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string][]string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			raw:  "-type User",
+			want: map[string][]string{"type": {"User"}, "suffix": {"Sorted"}},
+		},
+		{
+			name: "all forms",
+			raw:  `-type User --suffix=ByEmail limit=10 -verbose -order desc`,
+			want: map[string][]string{
+				"type":    {"User"},
+				"suffix":  {"ByEmail"},
+				"limit":   {"10"},
+				"verbose": {"true"},
+				"order":   {"desc"},
+			},
+		},
+		{
+			name: "bool with value",
+			raw:  "-type User -verbose=false",
+			want: map[string][]string{"type": {"User"}, "suffix": {"Sorted"}, "verbose": {"false"}},
+		},
+		{
+			name: "repeated",
+			raw:  `-type User -tag a -tag "b c" tag=é`,
+			want: map[string][]string{"type": {"User"}, "suffix": {"Sorted"}, "tag": {"a", "b c", "é"}},
+		},
+		{
+			name: "non-ascii value",
+			raw:  "-type Åse",
+			want: map[string][]string{"type": {"Åse"}, "suffix": {"Sorted"}},
+		},
+		{name: "required", raw: "-suffix X", wantErr: true},
+		{name: "unknown", raw: "-type User -color red", wantErr: true},
+		{name: "positional", raw: "-type User red", wantErr: true},
+		{name: "missing value", raw: "-type", wantErr: true},
+		{name: "invalid int", raw: "-type User -limit ten", wantErr: true},
+		{name: "invalid bool", raw: "-type User -verbose=maybe", wantErr: true},
+		{name: "not in enum", raw: "-type User -order random", wantErr: true},
+		{name: "passed twice", raw: "-type User -type Work", wantErr: true},
+		{name: "unterminated quote", raw: `-type "User`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tg.ParseArgs(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseArgs(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.values, tt.want) {
+				t.Errorf("ParseArgs(%q) = %q, want %q", tt.raw, got.values, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgumentsGetters(t *testing.T) {
+	tg := TemplateGenerator{Args: []Arg{
+		{Name: "n", Type: IntArg, Repeated: true},
+		{Name: "b", Type: BoolArg},
+		{Name: "s"},
+	}}
+	args, err := tg.ParseArgs("-n 1 -n 2 -b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := args.Int("n"); got != 2 {
+		t.Errorf("Int(n) = %d, want 2", got)
+	}
+	if got := args.Ints("n"); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("Ints(n) = %v, want [1 2]", got)
+	}
+	if !args.Bool("b") {
+		t.Error("Bool(b) = false, want true")
+	}
+	if args.Has("s") || args.String("s") != "" {
+		t.Errorf("s should not be set, got %q", args.String("s"))
+	}
+}
+
+func TestParseDirectiveArgs(t *testing.T) {
+	d, ok := ParseDirective(`// simplegen:label -text voilà -doc "Åse är här"`)
+	if !ok {
+		t.Fatal("directive is not parsed")
+	}
+	want := []string{"-text", "voilà", "-doc", "Åse är här"}
+	if !reflect.DeepEqual(d.Args, want) {
+		t.Errorf("Args = %q, want %q", d.Args, want)
+	}
+}
@@ -0,0 +1,65 @@
+package simplegen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// backup keeps previous content of files changed in output to roll changes back.
+// It's used by transactional Generate.
+type backup struct {
+	output Output
+	files  []savedFile
+	saved  map[string]struct{}
+}
+
+// savedFile is a file content before change.
+type savedFile struct {
+	name    string
+	content []byte
+	existed bool
+}
+
+func newBackup(output Output) *backup {
+	return &backup{output: output, saved: make(map[string]struct{})}
+}
+
+// save remembers current content of file before it's changed. Does nothing for nil backup.
+func (b *backup) save(name string) error {
+	if b == nil {
+		return nil
+	}
+	if _, ok := b.saved[name]; ok {
+		return nil
+	}
+	content, err := b.output.ReadFile(name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	b.saved[name] = struct{}{}
+	b.files = append(b.files, savedFile{name: name, content: content, existed: err == nil})
+	return nil
+}
+
+// restore brings saved files back in reverse order of change.
+func (b *backup) restore() error {
+	var errs Errors
+	for i := len(b.files) - 1; i >= 0; i-- {
+		file := b.files[i]
+
+		var err error
+		if file.existed {
+			err = b.output.WriteFile(file.name, file.content)
+		} else if err = b.output.RemoveFile(file.name); errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, &WriteError{Path: file.name, Err: fmt.Errorf("cannot restore file: %w", err)})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package simplegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// FileDiff describes generated file which differs from existing one.
+type FileDiff struct {
+	Path string
+	// Diff is unified diff between existing file and generated content,
+	// file names in it are relative to working directory like in other errors.
+	Diff string
+}
+
+// CheckError lists generated files which are not up to date.
+type CheckError struct {
+	// Stale files exist, but their content differs from generated one.
+	Stale []FileDiff
+	// Missing files should be generated, but don't exist.
+	Missing []FileDiff
+	// Extraneous files were generated before, but have nothing to generate now.
+	Extraneous []FileDiff
+}
+
+func (e *CheckError) Error() string {
+	b := strings.Builder{}
+	b.WriteString("generated files are not up to date:")
+	for _, group := range []struct {
+		name  string
+		files []FileDiff
+	}{
+		{"stale", e.Stale},
+		{"missing", e.Missing},
+		{"extraneous", e.Extraneous},
+	} {
+		for _, f := range group.files {
+			fmt.Fprintf(&b, "\n%s: %s", group.name, shortPath(f.Path))
+		}
+	}
+	for _, files := range [][]FileDiff{e.Stale, e.Missing, e.Extraneous} {
+		for _, f := range files {
+			b.WriteString("\n")
+			b.WriteString(f.Diff)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Check finds magic comments in packages, renders generated files in memory and compares them with files in output.
+// Nothing is written. Returns *CheckError if any generated file is stale, missing or extraneous.
+func (sg *SimpleGenerator) Check() error {
+	if err := sg.collect(); err != nil {
+		return err
+	}
+
+	files, err := sg.render()
+	if err != nil {
+		return err
+	}
+
+	return sg.check(files)
+}
+
+// check compares rendered files with files in output.
+func (sg *SimpleGenerator) check(files []*generatedFile) error {
+	checkErr := &CheckError{}
+
+	for _, file := range files {
+		existing, err := sg.output.ReadFile(file.path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			checkErr.Missing = append(checkErr.Missing, FileDiff{
+				Path: file.path,
+				Diff: unifiedDiff("/dev/null", shortPath(file.path), nil, file.content),
+			})
+		case err != nil:
+			return err
+		case bytes.Equal(existing, file.content):
+			// up to date
+		case !isGenerated(existing):
+			// Generate would refuse to overwrite it
+			_, err = sg.checkOverwrite(file)
+			return err
+		default:
+			checkErr.Stale = append(checkErr.Stale, FileDiff{
+				Path: file.path,
+				Diff: unifiedDiff(shortPath(file.path), shortPath(file.path), existing, file.content),
+			})
+		}
+	}
+
+	extraneous, err := sg.orphanedFiles()
+	if err != nil {
+		return err
+	}
+	for _, fileName := range sortedKeys(extraneous) {
+		checkErr.Extraneous = append(checkErr.Extraneous, FileDiff{
+			Path: fileName,
+			Diff: unifiedDiff(shortPath(fileName), "/dev/null", extraneous[fileName], nil),
+		})
+	}
+
+	if len(checkErr.Stale)+len(checkErr.Missing)+len(checkErr.Extraneous) > 0 {
+		return checkErr
+	}
+	return nil
+}
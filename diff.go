@@ -0,0 +1,204 @@
+package simplegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is a number of unchanged lines around changes in unified diff.
+const diffContext = 3
+
+type diffOp byte
+
+const (
+	diffEqual  diffOp = ' '
+	diffDelete diffOp = '-'
+	diffInsert diffOp = '+'
+)
+
+type diffEdit struct {
+	op   diffOp
+	line string
+}
+
+// unifiedDiff returns unified diff between old and new content, empty string if they are equal.
+func unifiedDiff(oldName, newName string, oldContent, newContent []byte) string {
+	edits := diffLines(splitLines(string(oldContent)), splitLines(string(newContent)))
+
+	// indexes of changed lines
+	var changes []int
+	for i, e := range edits {
+		if e.op != diffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	// line numbers in old and new content before each edit
+	oldLines := make([]int, len(edits)+1)
+	newLines := make([]int, len(edits)+1)
+	for i, e := range edits {
+		oldLines[i+1], newLines[i+1] = oldLines[i], newLines[i]
+		if e.op != diffInsert {
+			oldLines[i+1]++
+		}
+		if e.op != diffDelete {
+			newLines[i+1]++
+		}
+	}
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	for i := 0; i < len(changes); {
+		// group changes which are close to each other into one hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*diffContext {
+			j++
+		}
+		start := changes[i] - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := changes[j] + diffContext + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		oldCount := oldLines[end] - oldLines[start]
+		newCount := newLines[end] - newLines[start]
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldLines[start], oldCount), hunkRange(newLines[start], newCount))
+		for _, e := range edits[start:end] {
+			b.WriteByte(byte(e.op))
+			b.WriteString(e.line)
+			b.WriteByte('\n')
+		}
+
+		i = j + 1
+	}
+	return b.String()
+}
+
+// hunkRange formats hunk range, start is a zero-based line number.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines finds the shortest edit script between a and b.
+func diffLines(a, b []string) []diffEdit {
+	// common prefix and suffix don't need to be diffed
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]diffEdit, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		edits = append(edits, diffEdit{op: diffEqual, line: line})
+	}
+	edits = append(edits, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, diffEdit{op: diffEqual, line: line})
+	}
+	return edits
+}
+
+// myers finds the shortest edit script with Myers algorithm.
+func myers(a, b []string) []diffEdit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		edits := make([]diffEdit, 0, n+m)
+		for _, line := range a {
+			edits = append(edits, diffEdit{op: diffDelete, line: line})
+		}
+		for _, line := range b {
+			edits = append(edits, diffEdit{op: diffInsert, line: line})
+		}
+		return edits
+	}
+
+	maxD := n + m
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+
+	// trace keeps v[-d-1:d+1] state before each step d to backtrack the path
+	var trace [][]int
+	found := false
+	for d := 0; d <= maxD && !found; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	edits := make([]diffEdit, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		// snapshot index of diagonal k is k+d+1
+		snap := trace[d]
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && snap[k-1+d+1] < snap[k+1+d+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := snap[prevK+d+1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			edits = append(edits, diffEdit{op: diffEqual, line: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, diffEdit{op: diffInsert, line: b[y-1]})
+			} else {
+				edits = append(edits, diffEdit{op: diffDelete, line: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,57 @@
+package simplegen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Directive is a parsed magic comment.
+//
+//	// simplegen:sort-by-keys -type *models.User -suffix ByEmail
+//
+// Generator -> "sort-by-keys"
+// Args -> ["-type", "*models.User", "-suffix", "ByEmail"].
+type Directive struct {
+	// Generator is an exact generator name from magic comment.
+	Generator GeneratorName
+	// Args contains the rest of magic comment line split into arguments, quotes are respected.
+	Args []string
+	// Raw is the rest of magic comment line as is.
+	Raw string
+}
+
+// ParseDirective parses magic comment in form of `//simplegen:<name> args...` or `// simplegen:<name> args...`.
+// Returns false if text is not a magic comment.
+func ParseDirective(text string) (*Directive, bool) {
+	s, ok := strings.CutPrefix(text, "//")
+	if !ok {
+		return nil, false
+	}
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+
+	s, ok = strings.CutPrefix(s, CmdKey+":")
+	if !ok {
+		return nil, false
+	}
+
+	name, raw := s, ""
+	if i := strings.IndexFunc(s, unicode.IsSpace); i >= 0 {
+		name, raw = s[:i], s[i:]
+	}
+	if name == "" {
+		return nil, false
+	}
+	raw = strings.TrimSpace(raw)
+
+	args, err := splitArgs(raw)
+	if err != nil {
+		// malformed quotes, generator will get error from ParseArgs
+		args = strings.Fields(raw)
+	}
+
+	return &Directive{
+		Generator: GeneratorName(name),
+		Args:      args,
+		Raw:       raw,
+	}, true
+}
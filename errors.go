@@ -0,0 +1,180 @@
+package simplegen
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNotGenerated is returned when existing file should be overwritten, but it's not generated by simplegen.
+var ErrNotGenerated = errors.New("file is not generated by simplegen")
+
+// Errors is a list of errors occurred during generation.
+// errors.Is and errors.As look through every error of the list.
+type Errors []error
+
+func (e Errors) Error() string {
+	return errors.Join(e...).Error()
+}
+
+// Unwrap returns errors of the list.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// add appends error to the list, nested lists are flattened.
+func (e Errors) add(err error) Errors {
+	if err == nil {
+		return e
+	}
+	if list, ok := err.(Errors); ok {
+		for _, nested := range list {
+			e = e.add(nested)
+		}
+		return e
+	}
+	return append(e, err)
+}
+
+// AnnotationError is returned when magic comment can't be processed: arguments are invalid
+// or generator func failed.
+type AnnotationError struct {
+	Generator GeneratorName
+	// Pos is a position of annotated declaration or magic comment.
+	Pos token.Position
+	// TypeName is a name of annotated declaration.
+	TypeName string
+	Err      error
+}
+
+func (e *AnnotationError) Error() string {
+	if e.Pos.Filename == "" {
+		return fmt.Sprintf("%s: %s", e.Generator, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %s", shortPosition(e.Pos), e.Generator, e.Err)
+}
+
+func (e *AnnotationError) Unwrap() error {
+	return e.Err
+}
+
+// TemplateError is returned when generator template can't be parsed or executed.
+type TemplateError struct {
+	Generator GeneratorName
+	// PkgPath is a path of package template was executed for, empty if template can't be parsed.
+	PkgPath string
+	// Line is a line of template where error occurred, 0 if unknown.
+	Line int
+	// TypeName is a name of annotated declaration which spec failed template, empty if unknown.
+	TypeName string
+	// Pos is a position of annotated declaration which spec failed template.
+	Pos token.Position
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	if e.Pos.Filename != "" {
+		return fmt.Sprintf("%s: %s: %s", shortPosition(e.Pos), e.Generator, e.Err)
+	}
+	if e.PkgPath == "" {
+		return fmt.Sprintf("%s: %s", e.Generator, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Generator, e.PkgPath, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// templateErrorLine extracts template line from text/template error like "template: name:12: ...".
+func templateErrorLine(err error) int {
+	m := templateErrorLineRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line
+}
+
+var templateErrorLineRe = regexp.MustCompile(`^template: [^:]*:(\d+)`)
+
+// FormatError is returned when generated code can't be formatted, usually because it's not valid Go code.
+type FormatError struct {
+	Generator GeneratorName
+	// Path is a path of generated file.
+	Path string
+	// Source is a raw unformatted source of generated file.
+	Source []byte
+	// Line is a line of Source where error occurred, 0 if unknown.
+	Line int
+	// TemplateLine is a line of template which produced Line, 0 if unknown.
+	TemplateLine int
+	// Snippet contains lines of Source around Line with error lines highlighted.
+	Snippet string
+	Err     error
+}
+
+func (e *FormatError) Error() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "%s: %s: cannot format generated code: %s", shortPath(e.Path), e.Generator, e.Err)
+	if e.TemplateLine > 0 {
+		fmt.Fprintf(&b, " (template line %d)", e.TemplateLine)
+	}
+	if e.Snippet != "" {
+		b.WriteString("\n")
+		b.WriteString(e.Snippet)
+	}
+	return b.String()
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError is returned when generated file can't be written or orphaned file can't be removed.
+type WriteError struct {
+	// Generator is empty for orphaned files.
+	Generator GeneratorName
+	Path      string
+	Err       error
+}
+
+func (e *WriteError) Error() string {
+	if e.Generator == "" {
+		return fmt.Sprintf("%s: %s", shortPath(e.Path), e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %s", shortPath(e.Path), e.Generator, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// shortPosition returns file:line with file relative to working directory.
+func shortPosition(pos token.Position) string {
+	if !pos.IsValid() {
+		return shortPath(pos.Filename)
+	}
+	return fmt.Sprintf("%s:%d", shortPath(pos.Filename), pos.Line)
+}
+
+// shortPath returns path relative to working directory if path is inside it.
+func shortPath(path string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
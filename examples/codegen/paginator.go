@@ -2,8 +2,6 @@ package codegen
 
 import (
 	"github.com/AlwxSin/simplegen"
-	"go/ast"
-	"golang.org/x/tools/go/packages"
 )
 
 var PaginatorTemplate = `
@@ -41,20 +39,15 @@ func New{{$struct.Name}}ListPaginated(paginateOptions PaginateOptions) (*{{$stru
 {{ end }}
 `
 
-func Paginator(
-	sg *simplegen.SimpleGenerator,
-	pkg *packages.Package,
-	node *ast.TypeSpec,
-	comment *ast.Comment,
-) (templateData simplegen.SpecData, imports []string, err error) {
-	imports = append(imports, "strconv")
+func Paginator(sg *simplegen.SimpleGenerator, a *simplegen.Annotation) (templateData simplegen.SpecData, imports []simplegen.Import, err error) {
+	imports = append(imports, simplegen.Import{Path: "strconv"})
 
 	type PaginatorTypeSpec struct {
 		Name string
 	}
 
 	tmplData := &PaginatorTypeSpec{
-		Name: node.Name.Name,
+		Name: a.Name(),
 	}
 	return simplegen.SpecData(tmplData), imports, nil
 }
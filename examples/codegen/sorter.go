@@ -1,7 +1,6 @@
 package codegen
 
 import (
-	"flag"
 	"fmt"
 	"github.com/AlwxSin/simplegen"
 	"go/ast"
@@ -34,35 +33,28 @@ func {{$spec.Type.Name}}List{{$spec.Suffix}}SortByKeys(vs {{if not $spec.Type.Is
 {{end}}
 `
 
+var SorterArgs = []simplegen.Arg{
+	{Name: "type", Required: true, Usage: "Type for which need to generate"},
+	{Name: "suffix", Usage: "Suffix for generated function"},
+	{Name: "fieldName", Default: "ID", Usage: "Field name which be used as identifier"},
+	{Name: "fieldType", Default: "int", Usage: "Field type"},
+}
+
 func Sorter(
 	sg *simplegen.SimpleGenerator,
 	pkg *packages.Package,
 	node *ast.TypeSpec,
 	comment *ast.Comment,
 ) (templateData simplegen.SpecData, imports []string, err error) {
+	args := sg.Args(comment)
+
 	var (
-		typeName  string
-		fieldName string
-		fieldType string
-		suffix    string
+		typeName  = args.String("type")
+		fieldName = args.String("fieldName")
+		fieldType = args.String("fieldType")
+		suffix    = args.String("suffix")
 	)
 
-	fs := flag.FlagSet{}
-	fs.StringVar(&typeName, "type", "", "Type for which need to generate")
-	fs.StringVar(&suffix, "suffix", "", "Suffix for generated function")
-	fs.StringVar(&fieldName, "fieldName", "ID", "Field name which be used as identifier")
-	fs.StringVar(&fieldType, "fieldType", "int", "Field type")
-
-	s := strings.TrimPrefix(comment.Text, "//")
-	s = strings.TrimSpace(s)
-	s = strings.TrimPrefix(s, simplegen.CmdKey+":sort-by-keys ")
-	args := strings.Split(s, " ")
-
-	err = fs.Parse(args)
-	if err != nil {
-		return nil, nil, err
-	}
-
 	parts := partsRe.FindStringSubmatch(typeName)
 	if len(parts) != 4 {
 		return nil, nil, fmt.Errorf("type must be in the form []*github.com/import/path.Name")
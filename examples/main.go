@@ -5,6 +5,8 @@ import (
 	"flag"
 	"fmt"
 	"github.com/AlwxSin/simplegen"
+	"os"
+	"runtime"
 	"text/template"
 )
 
@@ -13,45 +15,63 @@ import (
 // go run main.go -package examples/my_project/models -package examples/my_project/responses
 func main() {
 	var (
-		help bool
-		pn   simplegen.PackageNames
+		help  bool
+		check bool
+		pn    simplegen.PackageNames
 	)
 
 	flag.BoolVar(&help, "h", false, "Show this help text")
 	flag.BoolVar(&help, "help", false, "")
+	flag.BoolVar(&check, "check", false, "Check generated files are up to date without writing them")
 	flag.Var(&pn, "package", "Package where simplegen should find magic comments")
 	flag.Parse()
 
-	if help {
-		flag.PrintDefaults()
-		return
-	}
-
-	pn = simplegen.PackageNames{"examples/my_project/models", "examples/my_project/responses"}
-
-	sg, err := simplegen.NewSimpleGenerator(pn, simplegen.GeneratorsMap{
+	generators := simplegen.GeneratorsMap{
 		"paginator": simplegen.TemplateGenerator{
-			Template:      codegen.PaginatorTemplate,
-			GeneratorFunc: codegen.Paginator,
+			Template:       codegen.PaginatorTemplate,
+			AnnotationFunc: codegen.Paginator,
+			Usage:          "Generates paginated list container.",
 		},
 		"settable-input": simplegen.TemplateGenerator{
-			Template:      codegen.SettableTemplate,
-			GeneratorFunc: codegen.Settable,
+			Template:       codegen.SettableTemplate,
+			AnnotationFunc: codegen.Settable,
+			Usage:          "Generates struct with Settable fields.",
 		},
 		"sort-by-keys": simplegen.TemplateGenerator{
 			Template:      codegen.SorterTemplate,
 			GeneratorFunc: codegen.Sorter,
+			Args:          codegen.SorterArgs,
+			Usage:         "Generates function to sort list by keys order.",
 		},
-	}, template.FuncMap{
+	}
+
+	if help {
+		flag.PrintDefaults()
+		fmt.Println()
+		generators.PrintUsage(os.Stdout)
+		return
+	}
+
+	pn = simplegen.PackageNames{"examples/my_project/models", "examples/my_project/responses"}
+
+	sg, err := simplegen.NewSimpleGenerator(pn, generators, template.FuncMap{
 		"formatSettableTags": codegen.FormatSettableTags,
-	})
+	}, simplegen.WithConcurrency(runtime.NumCPU()))
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	err = sg.Generate()
+	if check {
+		err = sg.Check()
+	} else {
+		err = sg.Generate()
+	}
 	if err != nil {
 		fmt.Println(err)
+		os.Exit(1)
+	}
+	if !check {
+		fmt.Println(sg.Report())
 	}
 }
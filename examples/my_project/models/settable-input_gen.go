@@ -22,12 +22,12 @@ func NewSettable[T any](value T) Settable[T] {
 
 // UserSettable allows to use User with Settable fields
 type UserSettable struct {
-	ID        Settable[int]        `json:&#34;id&#34; yaml:&#34;id&#34;`
-	CreatedAt Settable[*time.Time] `json:&#34;createdAt&#34; yaml:&#34;createdAt&#34;`
-	FirstName Settable[string]     `json:&#34;firstName&#34; yaml:&#34;firstName&#34;`
-	Email     Settable[string]     `json:&#34;email&#34; yaml:&#34;email&#34;`
-	Age       Settable[int]        `json:&#34;age&#34; yaml:&#34;age&#34;`
-	Settings  Settable[JSONB]      `json:&#34;settings&#34; yaml:&#34;settings&#34;`
+	ID        Settable[int]        `json:"id" yaml:"id"`
+	CreatedAt Settable[*time.Time] `json:"createdAt" yaml:"createdAt"`
+	FirstName Settable[string]     `json:"firstName" yaml:"firstName"`
+	Email     Settable[string]     `json:"email" yaml:"email"`
+	Age       Settable[int]        `json:"age" yaml:"age"`
+	Settings  Settable[JSONB]      `json:"settings" yaml:"settings"`
 }
 
 func (inp *User) ToSettable(inputFields map[string]interface{}) *UserSettable {
@@ -0,0 +1,264 @@
+package simplegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Field describes struct field.
+type Field struct {
+	Name string
+	// Type is a field type.
+	Type types.Type
+	// TypeString is a field type written relative to target package, see SimpleGenerator.TypeString.
+	TypeString string
+	// Tag is a raw struct tag.
+	Tag reflect.StructTag
+	// Tags are parsed Tag.
+	Tags Tags
+	// EmbeddedPath contains names of embedded fields the field is promoted through,
+	// ["Common"] for User.Common.ID, empty for own fields.
+	EmbeddedPath []string
+	// Embedded reports if field is embedded and was not flattened.
+	Embedded bool
+	Exported bool
+	// Doc is a text of field doc comment.
+	Doc string
+	// Comment is a text of field line comment.
+	Comment string
+	// Var is a field object.
+	Var *types.Var
+}
+
+// StructFieldsOptions configures SimpleGenerator.StructFields.
+type StructFieldsOptions struct {
+	// KeepEmbedded returns embedded fields as is instead of flattening fields of embedded structs.
+	KeepEmbedded bool
+	// ExportedOnly skips unexported fields.
+	ExportedOnly bool
+	// TargetPkg is a package which TypeString is written relative to. Package of struct by default.
+	TargetPkg *packages.Package
+}
+
+// fieldEntry is a field found while walking struct and its embedded structs.
+type fieldEntry struct {
+	v     *types.Var
+	tag   string
+	depth int
+	path  []string
+	// flattened embedded field is replaced by its own fields
+	flattened bool
+}
+
+// StructFields returns fields of struct type declared in package.
+// Fields of embedded structs (including pointers and structs from other packages) are flattened
+// following Go promotion rules: shallower field shadows deeper ones, fields with the same name
+// at the same depth are ambiguous and skipped. Unexported fields from other packages are skipped.
+// Returns fields in declaration order and imports required by their TypeString.
+func (sg *SimpleGenerator) StructFields(
+	pkg *packages.Package,
+	typeName string,
+	opts *StructFieldsOptions,
+) ([]*Field, []Import, error) {
+	if opts == nil {
+		opts = &StructFieldsOptions{}
+	}
+	target := opts.TargetPkg
+	if target == nil {
+		target = pkg
+	}
+
+	structType, err := sg.GetStructType(pkg, typeName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []*fieldEntry
+	collectFields(structType, 0, nil, map[*types.Named]bool{}, opts.KeepEmbedded, &entries)
+
+	// resolve promotion: name is accessible at the shallowest depth if it's unique there
+	minDepth := make(map[string]int)
+	count := make(map[string]int)
+	for _, e := range entries {
+		name := e.v.Name()
+		d, ok := minDepth[name]
+		switch {
+		case !ok || e.depth < d:
+			minDepth[name] = e.depth
+			count[name] = 1
+		case e.depth == d:
+			count[name]++
+		}
+	}
+
+	var (
+		fields  []*Field
+		imports []Import
+	)
+	for _, e := range entries {
+		name := e.v.Name()
+		if e.flattened || e.depth != minDepth[name] || count[name] > 1 {
+			continue
+		}
+		if !e.v.Exported() && (opts.ExportedOnly || e.v.Pkg() == nil || e.v.Pkg().Path() != target.PkgPath) {
+			continue
+		}
+
+		tags, err := ParseTag(e.tag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", typeName, name, err)
+		}
+
+		typeString, typeImports := sg.TypeString(e.v.Type(), target)
+		imports = append(imports, typeImports...)
+
+		field := &Field{
+			Name:         name,
+			Type:         e.v.Type(),
+			TypeString:   typeString,
+			Tag:          reflect.StructTag(e.tag),
+			Tags:         tags,
+			EmbeddedPath: e.path,
+			Embedded:     e.v.Embedded(),
+			Exported:     e.v.Exported(),
+			Var:          e.v,
+		}
+		if astField := sg.findASTField(e.v); astField != nil {
+			field.Doc = strings.TrimSpace(astField.Doc.Text())
+			field.Comment = strings.TrimSpace(astField.Comment.Text())
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, imports, nil
+}
+
+// collectFields walks struct fields in declaration order and goes into embedded structs.
+func collectFields(
+	structType *types.Struct,
+	depth int,
+	path []string,
+	visiting map[*types.Named]bool,
+	keepEmbedded bool,
+	entries *[]*fieldEntry,
+) {
+	for i := 0; i < structType.NumFields(); i++ {
+		v := structType.Field(i)
+		e := &fieldEntry{v: v, tag: structType.Tag(i), depth: depth, path: path}
+		*entries = append(*entries, e)
+
+		if !v.Embedded() || keepEmbedded {
+			continue
+		}
+		embedded, named := embeddedStruct(v.Type())
+		if embedded == nil || visiting[named] {
+			continue
+		}
+
+		e.flattened = true
+		if named != nil {
+			// embedded types can be recursive through pointers
+			visiting[named] = true
+		}
+		embeddedPath := append(append([]string(nil), path...), v.Name())
+		collectFields(embedded, depth+1, embeddedPath, visiting, keepEmbedded, entries)
+		if named != nil {
+			delete(visiting, named)
+		}
+	}
+}
+
+// embeddedStruct returns struct of embedded field type T or *T, nil if it's not a struct.
+func embeddedStruct(t types.Type) (*types.Struct, *types.Named) {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	structType, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil
+	}
+	named, ok := t.(*types.Named)
+	if ok {
+		// instances of generic type are recursive through origin type
+		named = named.Origin()
+	}
+	return structType, named
+}
+
+// findASTField finds ast.Field declaring field v.
+// Returns nil if source of field package can't be loaded.
+func (sg *SimpleGenerator) findASTField(v *types.Var) *ast.Field {
+	if v.Pkg() == nil {
+		return nil
+	}
+	// field can come from export data, so match it by file and line
+	pos := sg.Position(v)
+	if !pos.IsValid() {
+		return nil
+	}
+
+	srcPkg, err := sg.GetPackage(v.Pkg().Path())
+	if err != nil {
+		return nil
+	}
+
+	var found *ast.Field
+	for _, file := range srcPkg.Syntax {
+		if sg.Position(file).Filename != pos.Filename {
+			continue
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if found != nil {
+				return false
+			}
+			field, ok := n.(*ast.Field)
+			if !ok || sg.Position(field).Line != pos.Line {
+				return true
+			}
+			if containsName(field.Names, v.Name()) || (v.Embedded() && embeddedFieldName(field) == v.Name()) {
+				found = field
+			}
+			return true
+		})
+	}
+	return found
+}
+
+// embeddedFieldName returns name of embedded field, empty if field is not embedded.
+func embeddedFieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return ""
+	}
+	t := field.Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	switch expr := t.(type) {
+	case *ast.IndexExpr:
+		t = expr.X
+	case *ast.IndexListExpr:
+		t = expr.X
+	}
+	switch expr := t.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	default:
+		return ""
+	}
+}
+
+func containsName(names []*ast.Ident, name string) bool {
+	for _, ident := range names {
+		if ident.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package simplegen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStructFields(t *testing.T) {
+	const (
+		pkgPath   = "github.com/AlwxSin/simplegen/testdata/fields"
+		otherPath = pkgPath + "/other"
+	)
+	sg := newTestGenerator(t, PackageNames{"./testdata/fields"}, GeneratorsMap{})
+	pkg, err := sg.GetPackage(pkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPkg, err := sg.GetPackage(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		typeName string
+		opts     *StructFieldsOptions
+		// want are fields written as "Name TypeString EmbeddedPath"
+		want        []string
+		wantImports []Import
+		wantErr     bool
+	}{
+		{
+			// shallower fields shadow deeper ones, Dup and Name are ambiguous at the same depth,
+			// unexported hidden of other package is skipped
+			name:     "flattened",
+			typeName: "User",
+			want: []string{
+				"CommonID int [Common]",
+				"private bool [Common]",
+				`ID int [Base] json:"id" doc="ID doc" comment=""`,
+				"OnlyA int [A]",
+				`Shadowed int [] json:"shadowed"`,
+				`Email string [] json:"email,omitempty" doc="Email doc" comment="email comment"`,
+				"secret string []",
+				"Tags map[string][]*other.Other []",
+				"Created time.Time []",
+			},
+			wantImports: []Import{{Path: otherPath}, {Path: "time"}},
+		},
+		{
+			name:     "exported only",
+			typeName: "User",
+			opts:     &StructFieldsOptions{ExportedOnly: true},
+			want: []string{
+				"CommonID int [Common]",
+				`ID int [Base] json:"id" doc="ID doc" comment=""`,
+				"OnlyA int [A]",
+				`Shadowed int [] json:"shadowed"`,
+				`Email string [] json:"email,omitempty" doc="Email doc" comment="email comment"`,
+				"Tags map[string][]*other.Other []",
+				"Created time.Time []",
+			},
+			wantImports: []Import{{Path: otherPath}, {Path: "time"}},
+		},
+		{
+			name:     "keep embedded",
+			typeName: "User",
+			opts:     &StructFieldsOptions{KeepEmbedded: true},
+			want: []string{
+				"Common Common [] embedded",
+				"Base *other.Base [] embedded",
+				"A A [] embedded",
+				"B B [] embedded",
+				"Other other.Other [] embedded",
+				`Shadowed int [] json:"shadowed"`,
+				`Email string [] json:"email,omitempty" doc="Email doc" comment="email comment"`,
+				"secret string []",
+				"Tags map[string][]*other.Other []",
+				"Created time.Time []",
+			},
+			wantImports: []Import{{Path: otherPath}, {Path: otherPath}, {Path: otherPath}, {Path: "time"}},
+		},
+		{
+			// unexported fields are visible only in their own package
+			name:     "other target package",
+			typeName: "User",
+			opts:     &StructFieldsOptions{TargetPkg: otherPkg},
+			want: []string{
+				"CommonID int [Common]",
+				`ID int [Base] json:"id" doc="ID doc" comment=""`,
+				"hidden string [Base]",
+				"OnlyA int [A]",
+				`Shadowed int [] json:"shadowed"`,
+				`Email string [] json:"email,omitempty" doc="Email doc" comment="email comment"`,
+				"Tags map[string][]*Other []",
+				"Created time.Time []",
+			},
+			wantImports: []Import{{Path: "time"}},
+		},
+		{
+			// recursive pointer embedding is flattened once, deeper Node is shadowed by flattened one
+			name:     "recursive",
+			typeName: "Node",
+			want:     []string{"Value int []"},
+		},
+		{name: "bad tag", typeName: "BadTag", wantErr: true},
+		{name: "not struct", typeName: "NotStruct", wantErr: true},
+		{name: "not found", typeName: "Missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, imports, err := sg.StructFields(pkg, tt.typeName, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StructFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := make([]string, 0, len(fields))
+			for _, f := range fields {
+				got = append(got, fieldString(f))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StructFields() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if !reflect.DeepEqual(imports, tt.wantImports) {
+				t.Errorf("StructFields() imports = %+v, want %+v", imports, tt.wantImports)
+			}
+		})
+	}
+}
+
+func fieldString(f *Field) string {
+	s := fmt.Sprintf("%s %s %v", f.Name, f.TypeString, f.EmbeddedPath)
+	if f.Embedded {
+		s += " embedded"
+	}
+	if f.Tags != nil {
+		s += " " + f.Tags.String()
+	}
+	if f.Doc != "" || f.Comment != "" {
+		s += fmt.Sprintf(" doc=%q comment=%q", f.Doc, f.Comment)
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+package simplegen
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/imports"
+)
+
+// fixImports removes unused imports from generated file and adds missing ones.
+// Missing imports are looked up in loaded packages first, the rest is resolved by goimports.
+func (sg *SimpleGenerator) fixImports(fileName string, target *packages.Package, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	if sg.addMissingImports(fset, file, target) {
+		buf := bytes.Buffer{}
+		if err = format.Node(&buf, fset, file); err != nil {
+			return nil, err
+		}
+		src = buf.Bytes()
+	}
+
+	return imports.Process(fileName, src, &imports.Options{
+		Comments:  true,
+		TabIndent: true,
+		TabWidth:  8,
+	})
+}
+
+// addMissingImports adds imports of loaded packages for unresolved selectors like models.User.
+// Reports if any import was added.
+func (sg *SimpleGenerator) addMissingImports(fset *token.FileSet, file *ast.File, target *packages.Package) bool {
+	unresolved := make(map[*ast.Ident]struct{}, len(file.Unresolved))
+	for _, ident := range file.Unresolved {
+		unresolved[ident] = struct{}{}
+	}
+
+	imported := make(map[string]struct{}, len(file.Imports))
+	for _, spec := range file.Imports {
+		importPath, _ := strconv.Unquote(spec.Path.Value)
+		name := guessPackageName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		} else if pkgName := sg.packageName(importPath); pkgName != "" {
+			name = pkgName
+		}
+		imported[name] = struct{}{}
+	}
+
+	added := false
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if _, ok = unresolved[ident]; !ok {
+			return true
+		}
+		if _, ok = imported[ident.Name]; ok {
+			return true
+		}
+		if target.Types != nil && target.Types.Scope().Lookup(ident.Name) != nil {
+			// declared in another file of target package
+			return true
+		}
+
+		if importPath := sg.findPackage(ident.Name, sel.Sel.Name); importPath != "" && importPath != target.PkgPath {
+			astutil.AddImport(fset, file, importPath)
+			imported[ident.Name] = struct{}{}
+			added = true
+		}
+		return true
+	})
+	return added
+}
+
+// findPackage returns path of loaded package with given name which exports symbol.
+// Packages imported by scanned packages are considered as loaded too.
+// Returns empty string if there is no such package.
+func (sg *SimpleGenerator) findPackage(name, symbol string) string {
+	candidates := make(map[string]*types.Package)
+	sg.pkgsMu.Lock()
+	for _, pkg := range sg.pkgs {
+		if pkg.Types != nil {
+			candidates[pkg.PkgPath] = pkg.Types
+		}
+	}
+	sg.pkgsMu.Unlock()
+	for _, pkg := range sg.roots {
+		if pkg.Types == nil {
+			continue
+		}
+		for _, imp := range pkg.Types.Imports() {
+			candidates[imp.Path()] = imp
+		}
+	}
+
+	var paths []string
+	for importPath, pkg := range candidates {
+		if pkg.Name() != name {
+			continue
+		}
+		if obj := pkg.Scope().Lookup(symbol); obj != nil && obj.Exported() {
+			paths = append(paths, importPath)
+		}
+	}
+	if len(paths) == 0 {
+		return ""
+	}
+	sort.Strings(paths)
+	return paths[0]
+}
@@ -0,0 +1,312 @@
+package simplegen
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// namesTemplate writes names of annotated declarations, one per line.
+const namesTemplate = "{{ range .Specs }}// {{ . }}\n{{ end }}"
+
+// annotationName is a generator func which collects names of annotated declarations.
+func annotationName(_ *SimpleGenerator, a *Annotation) (SpecData, []Import, error) {
+	return a.Name(), nil, nil
+}
+
+// newTestGenerator loads packages from testdata.
+func newTestGenerator(t *testing.T, pkgNames PackageNames, generators GeneratorsMap, opts ...Option) *SimpleGenerator {
+	t.Helper()
+
+	sg, err := NewSimpleGenerator(pkgNames, generators, nil, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sg
+}
+
+// testdataFile returns absolute path of file in testdata package.
+func testdataFile(t *testing.T, pkg, name string) string {
+	t.Helper()
+
+	path, err := filepath.Abs(filepath.Join("testdata", pkg, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// generatedNames returns names written by namesTemplate.
+func generatedNames(content []byte) []string {
+	var names []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if name, ok := strings.CutPrefix(line, "// "); ok && !strings.HasPrefix(line, generatedComment) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestGeneratePackageLevelDeclarations(t *testing.T) {
+	sg := newTestGenerator(t, PackageNames{"./testdata/decls"}, GeneratorsMap{
+		"noop": {Template: namesTemplate, AnnotationFunc: annotationName},
+	})
+
+	files, err := sg.GenerateToMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, ok := files[testdataFile(t, "decls", "noop_gen.go")]
+	if !ok {
+		t.Fatalf("noop_gen.go is not generated, got %v", files)
+	}
+	// declarations inside functions are not annotated
+	want := []string{"Top", "TopConst", "TopFunc", "TopVar"}
+	if got := generatedNames(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("generated names = %v, want %v", got, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	noopFile := testdataFile(t, "decls", "noop_gen.go")
+	otherFile := testdataFile(t, "decls", "other_gen.go")
+	generated := []byte(generatedComment + "\npackage decls\n")
+
+	tests := []struct {
+		name      string
+		files     map[string][]byte
+		wantLines []string
+	}{
+		{
+			name:  "missing",
+			files: nil,
+			wantLines: []string{
+				"missing: testdata/decls/noop_gen.go",
+				"+++ testdata/decls/noop_gen.go",
+			},
+		},
+		{
+			name: "stale and extraneous",
+			files: map[string][]byte{
+				noopFile:  generated,
+				otherFile: generated,
+			},
+			wantLines: []string{
+				"stale: testdata/decls/noop_gen.go",
+				"extraneous: testdata/decls/other_gen.go",
+				"--- testdata/decls/noop_gen.go",
+				"--- testdata/decls/other_gen.go",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := newTestGenerator(t, PackageNames{"./testdata/decls"}, GeneratorsMap{
+				"noop":  {Template: namesTemplate, AnnotationFunc: annotationName},
+				"other": {Template: namesTemplate, AnnotationFunc: annotationName},
+			}, WithOutput(NewMemoryOutput(tt.files)))
+
+			err := sg.Check()
+			var checkErr *CheckError
+			if !errors.As(err, &checkErr) {
+				t.Fatalf("Check() = %v, want *CheckError", err)
+			}
+			lines := strings.Split(checkErr.Error(), "\n")
+			for _, want := range tt.wantLines {
+				if !containsLine(lines, want) {
+					t.Errorf("Check() error has no line %q:\n%s", want, checkErr)
+				}
+			}
+		})
+	}
+
+	// up to date files pass the check
+	sg := newTestGenerator(t, PackageNames{"./testdata/decls"}, GeneratorsMap{
+		"noop": {Template: namesTemplate, AnnotationFunc: annotationName},
+	})
+	files, err := sg.GenerateToMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sg.output = NewMemoryOutput(files)
+	if err = sg.Check(); err != nil {
+		t.Errorf("Check() of up to date files = %v", err)
+	}
+}
+
+func containsLine(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGeneratePackageNotFound(t *testing.T) {
+	const missing = "github.com/AlwxSin/simplegen/testdata/nope"
+	out := NewMemoryOutput(nil)
+	sg := newTestGenerator(t, PackageNames{"./testdata/decls", missing}, GeneratorsMap{
+		"noop": {Template: namesTemplate, AnnotationFunc: annotationName},
+	}, WithOutput(out))
+
+	err := sg.Generate()
+	if err == nil || !strings.Contains(err.Error(), "cannot load package "+missing) {
+		t.Errorf("Generate() = %v, want error about %s", err, missing)
+	}
+	// found package is generated anyway
+	if _, ok := out.Files()[testdataFile(t, "decls", "noop_gen.go")]; !ok {
+		t.Errorf("noop_gen.go is not written, got %v", out.Files())
+	}
+
+	if err = sg.Check(); err == nil || !strings.Contains(err.Error(), "cannot load package "+missing) {
+		t.Errorf("Check() = %v, want error about %s", err, missing)
+	}
+}
+
+func TestGenerateOrphans(t *testing.T) {
+	noopFile := testdataFile(t, "decls", "noop_gen.go")
+	otherFile := testdataFile(t, "decls", "other_gen.go")
+	generated := []byte(generatedComment + "\npackage decls\n")
+	handWritten := []byte("package decls\n")
+
+	tests := []struct {
+		name       string
+		existing   []byte
+		opts       []Option
+		wantExists bool
+		want       Report
+	}{
+		{
+			name:     "removed",
+			existing: generated,
+			want:     Report{Written: []string{noopFile}, Removed: []string{otherFile}},
+		},
+		{
+			name:       "kept",
+			existing:   generated,
+			opts:       []Option{WithKeepOrphans()},
+			wantExists: true,
+			want:       Report{Written: []string{noopFile}, Orphaned: []string{otherFile}},
+		},
+		{
+			name:       "hand-written",
+			existing:   handWritten,
+			wantExists: true,
+			want:       Report{Written: []string{noopFile}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := NewMemoryOutput(map[string][]byte{otherFile: tt.existing})
+			sg := newTestGenerator(t, PackageNames{"./testdata/decls"}, GeneratorsMap{
+				"noop":  {Template: namesTemplate, AnnotationFunc: annotationName},
+				"other": {Template: namesTemplate, AnnotationFunc: annotationName},
+			}, append(tt.opts, WithOutput(out))...)
+
+			if err := sg.Generate(); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := out.Files()[otherFile]; ok != tt.wantExists {
+				t.Errorf("other_gen.go exists = %v, want %v", ok, tt.wantExists)
+			}
+			if got := sg.Report(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Report() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateRefusesHandWrittenFiles(t *testing.T) {
+	noopFile := testdataFile(t, "decls", "noop_gen.go")
+	handWritten := []byte("package decls\n\n" + generatedComment + "\n")
+	out := NewMemoryOutput(map[string][]byte{noopFile: handWritten})
+	sg := newTestGenerator(t, PackageNames{"./testdata/decls"}, GeneratorsMap{
+		"noop": {Template: namesTemplate, AnnotationFunc: annotationName},
+	}, WithOutput(out))
+
+	err := sg.Generate()
+	if !errors.Is(err, ErrNotGenerated) {
+		t.Fatalf("Generate() = %v, want ErrNotGenerated", err)
+	}
+	var writeErr *WriteError
+	if !errors.As(err, &writeErr) || writeErr.Path != noopFile || writeErr.Generator != "noop" {
+		t.Errorf("Generate() = %#v, want WriteError of noop for %s", writeErr, noopFile)
+	}
+	if got := out.Files()[noopFile]; string(got) != string(handWritten) {
+		t.Errorf("hand-written file is changed:\n%s", got)
+	}
+	if err = sg.Check(); !errors.Is(err, ErrNotGenerated) {
+		t.Errorf("Check() = %v, want ErrNotGenerated", err)
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		content string
+		want    bool
+	}{
+		{content: generatedComment + "\npackage p\n", want: true},
+		{content: "// +build tools\n\n" + generatedComment + "\npackage p\n", want: true},
+		{content: "  " + generatedComment + "  \npackage p\n", want: true},
+		{content: "package p\n" + generatedComment + "\n", want: false},
+		{content: "// Code generated by other tool, DO NOT EDIT.\npackage p\n", want: false},
+		{content: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := isGenerated([]byte(tt.content)); got != tt.want {
+			t.Errorf("isGenerated(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestNewSimpleGeneratorValidatesBeforeLoading(t *testing.T) {
+	// packages can't be loaded with invalid go flags
+	t.Setenv("GOFLAGS", "-invalid-flag")
+
+	_, err := NewSimpleGenerator(PackageNames{"./testdata/decls"}, GeneratorsMap{
+		"bad": {Template: "{{ range .Specs }}", AnnotationFunc: annotationName},
+	}, nil)
+	var tmplErr *TemplateError
+	if !errors.As(err, &tmplErr) {
+		t.Errorf("NewSimpleGenerator() = %v, want TemplateError before packages are loaded", err)
+	}
+
+	_, err = NewSimpleGenerator(PackageNames{"./testdata/decls"}, GeneratorsMap{
+		"noop": {Template: namesTemplate, AnnotationFunc: annotationName},
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "cannot load packages") {
+		t.Errorf("NewSimpleGenerator() = %v, want load error", err)
+	}
+}
+
+func TestGenerateOtherTargetPackage(t *testing.T) {
+	const targetPath = "github.com/AlwxSin/simplegen/testdata/targets/target"
+	// types of both packages are written relative to target package, their models packages collide there
+	sg := newTestGenerator(t, PackageNames{"./testdata/targets/a", "./testdata/targets/b", targetPath}, GeneratorsMap{
+		"target": {Template: namesTemplate, AnnotationFunc: func(sg *SimpleGenerator, a *Annotation) (SpecData, []Import, error) {
+			target, err := sg.GetPackage(targetPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			typeString, _ := sg.TypeString(a.Object.Type(), target)
+			return typeString, nil, nil
+		}},
+	}, WithConcurrency(2))
+
+	files, err := sg.GenerateToMemory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// import names of other target are assigned for each annotated package, they don't depend on scheduling
+	for pkg, want := range map[string]string{"a": "models.A", "b": "models.B"} {
+		content := files[testdataFile(t, filepath.Join("targets", pkg), "target_gen.go")]
+		if got := generatedNames(content); !reflect.DeepEqual(got, []string{want}) {
+			t.Errorf("%s: generated names = %v, want [%s]", pkg, got, want)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package simplegen
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Import describes package imported by generated file.
+type Import struct {
+	Path string
+	// Alias is a name for imported package, optional.
+	// If alias is not set, SimpleGenerator uses package name
+	// or assigns alias itself when name collides with other imports or identifiers of target package.
+	Alias string
+}
+
+// Imports converts import paths to Import list.
+func Imports(paths ...string) []Import {
+	imports := make([]Import, 0, len(paths))
+	for _, p := range paths {
+		imports = append(imports, Import{Path: p})
+	}
+	return imports
+}
+
+// importTable assigns unique names to packages imported into target package.
+// All files generated for target package share the same table,
+// so package gets the same name in every generated file.
+// Table is safe for concurrent use.
+type importTable struct {
+	mu     sync.Mutex
+	target *packages.Package
+	// names maps used name to import path
+	names map[string]string
+	// defaults maps import path to name used when alias is not requested
+	defaults map[string]string
+}
+
+func newImportTable(target *packages.Package) *importTable {
+	return &importTable{
+		target:   target,
+		names:    make(map[string]string),
+		defaults: make(map[string]string),
+	}
+}
+
+func (t *importTable) clone() *importTable {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := newImportTable(t.target)
+	for name, importPath := range t.names {
+		c.names[name] = importPath
+	}
+	for importPath, name := range t.defaults {
+		c.defaults[importPath] = name
+	}
+	return c
+}
+
+// resolve returns name for imported package. pkgName is a real package name, empty if unknown.
+// Returns empty name if import path is target package itself.
+func (t *importTable) resolve(imp Import, pkgName string) (string, error) {
+	if imp.Path == t.target.PkgPath {
+		return "", nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if imp.Alias != "" {
+		if p, ok := t.names[imp.Alias]; ok && p != imp.Path {
+			return "", fmt.Errorf("import alias %s of %q collides with import of %q", imp.Alias, imp.Path, p)
+		}
+		if _, ok := t.names[imp.Alias]; !ok && t.declared(imp.Alias) {
+			return "", fmt.Errorf("import alias %s of %q collides with identifier declared in package %s",
+				imp.Alias, imp.Path, t.target.PkgPath)
+		}
+		t.names[imp.Alias] = imp.Path
+		if _, ok := t.defaults[imp.Path]; !ok {
+			t.defaults[imp.Path] = imp.Alias
+		}
+		return imp.Alias, nil
+	}
+
+	if name, ok := t.defaults[imp.Path]; ok {
+		return name, nil
+	}
+
+	if pkgName == "" {
+		pkgName = guessPackageName(imp.Path)
+	}
+	name := pkgName
+	for i := 2; ; i++ {
+		if _, ok := t.names[name]; !ok && !t.declared(name) {
+			break
+		}
+		name = pkgName + strconv.Itoa(i)
+	}
+	t.names[name] = imp.Path
+	t.defaults[imp.Path] = name
+	return name, nil
+}
+
+// fileImport resolves name of imported package and returns import to write in generated file,
+// alias is set only if name differs from package name. Returns empty name for target package itself.
+func (t *importTable) fileImport(rawImp Import, pkgName string) (Import, string, error) {
+	name, err := t.resolve(rawImp, pkgName)
+	if err != nil || name == "" {
+		return Import{}, name, err
+	}
+
+	imp := Import{Path: rawImp.Path}
+	if needsAlias(rawImp.Path, name, pkgName) {
+		imp.Alias = name
+	}
+	return imp, name, nil
+}
+
+// qualifier writes package names of the table, like types.Qualifier.
+// Imports of packages other than target are passed to add.
+func (t *importTable) qualifier(add func(imp Import)) types.Qualifier {
+	return func(p *types.Package) string {
+		// import without alias can't fail
+		imp, name, _ := t.fileImport(Import{Path: p.Path()}, p.Name())
+		if name != "" {
+			add(imp)
+		}
+		return name
+	}
+}
+
+// defaultName returns name assigned to import path without alias.
+func (t *importTable) defaultName(importPath string) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	name, ok := t.defaults[importPath]
+	return name, ok
+}
+
+// declared reports if name is declared in target package scope.
+func (t *importTable) declared(name string) bool {
+	if t.target.Types == nil {
+		return false
+	}
+	return t.target.Types.Scope().Lookup(name) != nil
+}
+
+// needsAlias reports if import should be written with explicit name.
+// pkgName is a real package name, empty if unknown.
+func needsAlias(importPath, name, pkgName string) bool {
+	if pkgName != "" {
+		return name != pkgName
+	}
+	return name != path.Base(importPath)
+}
+
+// guessPackageName returns probable package name by import path.
+// "github.com/go-chi/chi/v5" -> "chi", "gopkg.in/yaml.v3" -> "yaml".
+func guessPackageName(importPath string) string {
+	elems := strings.Split(importPath, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	name, _, _ = strings.Cut(name, ".")
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return -1
+		}
+		return r
+	}, name)
+	if !token.IsIdentifier(name) {
+		return "pkg"
+	}
+	return name
+}
+
+// isMajorVersion reports if path element is a major version suffix like v2.
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(elem[1:])
+	return err == nil
+}
+
+// isStdLib reports if import path belongs to standard library.
+// Path is considered as standard if its first element has no dot and it's not from loaded modules.
+func (sg *SimpleGenerator) isStdLib(importPath string) bool {
+	for _, pkg := range sg.roots {
+		if pkg.Module == nil {
+			continue
+		}
+		if importPath == pkg.Module.Path || strings.HasPrefix(importPath, pkg.Module.Path+"/") {
+			return false
+		}
+	}
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// importTable returns import table of target package.
+func (sg *SimpleGenerator) importTable(target *packages.Package) *importTable {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+
+	table, ok := sg.imports[target]
+	if !ok {
+		table = newImportTable(target)
+		sg.imports[target] = table
+	}
+	return table
+}
+
+// forPackage returns copy of generator to pass to generator funcs of package.
+// Other target packages get import tables private to the copy: generator funcs of package are called
+// sequentially, so import names of other targets don't depend on packages processed in parallel.
+func (sg *SimpleGenerator) forPackage(pkg *packages.Package) *SimpleGenerator {
+	return &SimpleGenerator{
+		generatorState: sg.generatorState,
+		pkg:            pkg,
+		targets:        make(map[*packages.Package]*importTable),
+	}
+}
+
+// targetTable returns import table to write types relative to target package.
+// Copies of generator use import tables of their package for other targets, see forPackage.
+func (sg *SimpleGenerator) targetTable(target *packages.Package) *importTable {
+	if sg.pkg == nil || target == sg.pkg {
+		return sg.importTable(target)
+	}
+	table, ok := sg.targets[target]
+	if !ok {
+		table = newImportTable(target)
+		sg.targets[target] = table
+	}
+	return table
+}
+
+// packageName returns name of loaded package, empty if package is not loaded.
+func (sg *SimpleGenerator) packageName(importPath string) string {
+	sg.pkgsMu.Lock()
+	defer sg.pkgsMu.Unlock()
+
+	if pkg, ok := sg.pkgs[pkgPath(importPath)]; ok {
+		return pkg.Name
+	}
+	return ""
+}
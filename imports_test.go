@@ -0,0 +1,174 @@
+package simplegen
+
+import (
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func newTestTarget(declared ...string) *packages.Package {
+	pkg := types.NewPackage("example.com/app/target", "target")
+	for _, name := range declared {
+		pkg.Scope().Insert(types.NewVar(token.NoPos, pkg, name, types.Typ[types.Int]))
+	}
+	return &packages.Package{PkgPath: pkg.Path(), Name: pkg.Name(), Types: pkg}
+}
+
+func TestImportTableResolve(t *testing.T) {
+	type step struct {
+		imp     Import
+		pkgName string
+		want    string
+		wantErr bool
+	}
+	tests := []struct {
+		name     string
+		declared []string
+		steps    []step
+	}{
+		{
+			name: "package name",
+			steps: []step{
+				{imp: Import{Path: "time"}, want: "time"},
+				{imp: Import{Path: "example.com/app/models"}, pkgName: "models", want: "models"},
+			},
+		},
+		{
+			name: "target package itself",
+			steps: []step{
+				{imp: Import{Path: "example.com/app/target"}, want: ""},
+			},
+		},
+		{
+			name: "same path gets same name",
+			steps: []step{
+				{imp: Import{Path: "example.com/a/models"}, want: "models"},
+				{imp: Import{Path: "example.com/a/models"}, want: "models"},
+			},
+		},
+		{
+			name: "colliding names get suffix",
+			steps: []step{
+				{imp: Import{Path: "example.com/a/models"}, want: "models"},
+				{imp: Import{Path: "example.com/b/models"}, want: "models2"},
+				{imp: Import{Path: "example.com/c/models"}, want: "models3"},
+				{imp: Import{Path: "example.com/b/models"}, want: "models2"},
+			},
+		},
+		{
+			name:     "collision with declared identifier",
+			declared: []string{"models"},
+			steps: []step{
+				{imp: Import{Path: "example.com/a/models"}, want: "models2"},
+			},
+		},
+		{
+			name: "real package name differs from path",
+			steps: []step{
+				{imp: Import{Path: "github.com/go-chi/chi/v5"}, pkgName: "chi", want: "chi"},
+				{imp: Import{Path: "gopkg.in/yaml.v3"}, want: "yaml"},
+			},
+		},
+		{
+			name: "alias",
+			steps: []step{
+				{imp: Import{Path: "example.com/a/models", Alias: "am"}, want: "am"},
+				// default name of the path is the first alias
+				{imp: Import{Path: "example.com/a/models"}, want: "am"},
+				{imp: Import{Path: "example.com/a/models", Alias: "am"}, want: "am"},
+			},
+		},
+		{
+			name: "alias and default name of the same path",
+			steps: []step{
+				{imp: Import{Path: "example.com/a/models"}, want: "models"},
+				{imp: Import{Path: "example.com/a/models", Alias: "am"}, want: "am"},
+				{imp: Import{Path: "example.com/a/models"}, want: "models"},
+			},
+		},
+		{
+			name: "default name skips alias of other path",
+			steps: []step{
+				{imp: Import{Path: "example.com/b/models", Alias: "models"}, want: "models"},
+				{imp: Import{Path: "example.com/a/models"}, want: "models2"},
+			},
+		},
+		{
+			name: "alias collides with other import",
+			steps: []step{
+				{imp: Import{Path: "example.com/a/models"}, want: "models"},
+				{imp: Import{Path: "example.com/b/models", Alias: "models"}, wantErr: true},
+			},
+		},
+		{
+			name:     "alias collides with declared identifier",
+			declared: []string{"m"},
+			steps: []step{
+				{imp: Import{Path: "example.com/a/models", Alias: "m"}, wantErr: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := newImportTable(newTestTarget(tt.declared...))
+			for _, s := range tt.steps {
+				got, err := table.resolve(s.imp, s.pkgName)
+				if (err != nil) != s.wantErr {
+					t.Fatalf("resolve(%+v) error = %v, wantErr %v", s.imp, err, s.wantErr)
+				}
+				if got != s.want {
+					t.Errorf("resolve(%+v) = %q, want %q", s.imp, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	tests := map[string]string{
+		"time":                       "time",
+		"example.com/app/models":     "models",
+		"github.com/go-chi/chi/v5":   "chi",
+		"gopkg.in/yaml.v3":           "yaml",
+		"example.com/my-pkg":         "mypkg",
+		"example.com/go-redis/redis": "redis",
+		"example.com/v2":             "example",
+		"example.com/123":            "pkg",
+	}
+	for importPath, want := range tests {
+		if got := guessPackageName(importPath); got != want {
+			t.Errorf("guessPackageName(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
+
+func TestImportTableQualifier(t *testing.T) {
+	table := newImportTable(newTestTarget())
+	var imports []Import
+	qualifier := table.qualifier(func(imp Import) {
+		imports = append(imports, imp)
+	})
+
+	user := types.NewNamed(types.NewTypeName(token.NoPos, types.NewPackage("example.com/a/models", "models"), "User", nil), types.Typ[types.Int], nil)
+	other := types.NewNamed(types.NewTypeName(token.NoPos, types.NewPackage("example.com/b/models", "models"), "User", nil), types.Typ[types.Int], nil)
+	local := types.NewNamed(types.NewTypeName(token.NoPos, newTestTarget().Types, "Local", nil), types.Typ[types.Int], nil)
+	chi := types.NewNamed(types.NewTypeName(token.NoPos, types.NewPackage("github.com/go-chi/chi/v5", "chi"), "Mux", nil), types.Typ[types.Int], nil)
+
+	got := types.TypeString(types.NewMap(user, types.NewSlice(other)), qualifier) + " " +
+		types.TypeString(local, qualifier) + " " +
+		types.TypeString(types.NewPointer(chi), qualifier)
+	if want := "map[models.User][]models2.User Local *chi.Mux"; got != want {
+		t.Errorf("TypeString() = %q, want %q", got, want)
+	}
+	want := []Import{
+		{Path: "example.com/a/models"},
+		{Path: "example.com/b/models", Alias: "models2"},
+		{Path: "github.com/go-chi/chi/v5"},
+	}
+	if !reflect.DeepEqual(imports, want) {
+		t.Errorf("imports = %+v, want %+v", imports, want)
+	}
+}
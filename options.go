@@ -0,0 +1,50 @@
+package simplegen
+
+// Option configures SimpleGenerator.
+type Option func(sg *SimpleGenerator)
+
+// WithOutput sets sink for generated files. OSOutput is used by default.
+func WithOutput(output Output) Option {
+	return func(sg *SimpleGenerator) {
+		sg.output = output
+	}
+}
+
+// WithKeepOrphans disables removing of orphaned files.
+// File is orphaned if it was generated by registered generator in scanned package,
+// but there are no magic comments for this generator in package anymore.
+// Orphaned files are only listed in Report.
+func WithKeepOrphans() Option {
+	return func(sg *SimpleGenerator) {
+		sg.keepOrphans = true
+	}
+}
+
+// WithFixImports enables goimports-style post-processing of generated files:
+// unused imports are removed and missing ones are added.
+// Missing imports are looked up in loaded packages first.
+func WithFixImports() Option {
+	return func(sg *SimpleGenerator) {
+		sg.fixImportsEnabled = true
+	}
+}
+
+// WithTransactional makes Generate all-or-nothing: nothing is written if any generator fails,
+// and output is restored if writing of any file fails. Every file is staged before any file is replaced,
+// see Stager.
+// By default successfully rendered files are written even if other generators fail.
+func WithTransactional() Option {
+	return func(sg *SimpleGenerator) {
+		sg.transactional = true
+	}
+}
+
+// WithConcurrency sets number of packages processed in parallel: generator funcs are called
+// and templates are rendered for several packages at once. Generators of the same package
+// are still processed sequentially, so generated files don't depend on concurrency.
+// Generator funcs must be safe for concurrent use. Packages are processed one by one by default.
+func WithConcurrency(n int) Option {
+	return func(sg *SimpleGenerator) {
+		sg.concurrency = n
+	}
+}
@@ -0,0 +1,190 @@
+package simplegen
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Output is a sink for generated files. SimpleGenerator passes absolute file paths to it.
+type Output interface {
+	// ReadFile returns content of existing file.
+	// Error should wrap fs.ErrNotExist if file doesn't exist.
+	ReadFile(name string) ([]byte, error)
+	// WriteFile (re)creates file with given content.
+	WriteFile(name string, content []byte) error
+	// RemoveFile removes file.
+	RemoveFile(name string) error
+}
+
+// Stager is implemented by outputs which can prepare file content before replacing file.
+// Transactional Generate stages every file first and replaces files only when all of them are staged.
+type Stager interface {
+	// StageFile prepares file content without changing file.
+	StageFile(name string, content []byte) (StagedFile, error)
+}
+
+// StagedFile is a file content prepared by Stager.
+type StagedFile interface {
+	// Commit replaces file with staged content.
+	Commit() error
+	// Discard drops staged content. It does nothing after Commit.
+	Discard() error
+}
+
+// stageFile stages file in output. Outputs which aren't Stager write file on Commit.
+func stageFile(output Output, name string, content []byte) (StagedFile, error) {
+	if stager, ok := output.(Stager); ok {
+		return stager.StageFile(name, content)
+	}
+	return &pendingFile{output: output, name: name, content: content}, nil
+}
+
+// pendingFile is a staged file of output without staging support.
+type pendingFile struct {
+	output  Output
+	name    string
+	content []byte
+}
+
+func (f *pendingFile) Commit() error {
+	return f.output.WriteFile(f.name, f.content)
+}
+
+func (f *pendingFile) Discard() error {
+	return nil
+}
+
+// OSOutput writes generated files to local filesystem. Default output of SimpleGenerator.
+type OSOutput struct{}
+
+func (OSOutput) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// WriteFile writes content to temporary file in the same directory and renames it to name,
+// so file is replaced atomically and never left half-written. Mode of existing file is kept.
+func (o OSOutput) WriteFile(name string, content []byte) error {
+	staged, err := o.StageFile(name, content)
+	if err != nil {
+		return err
+	}
+	return staged.Commit()
+}
+
+// StageFile writes content to temporary file in the same directory, Commit renames it to name.
+func (OSOutput) StageFile(name string, content []byte) (_ StagedFile, err error) {
+	mode := fs.FileMode(0o644)
+	if info, statErr := os.Stat(name); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(content); err != nil {
+		return nil, err
+	}
+	if err = f.Chmod(mode); err != nil {
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+	return &osStagedFile{name: name, tmp: f.Name()}, nil
+}
+
+func (OSOutput) RemoveFile(name string) error {
+	return os.Remove(name)
+}
+
+// osStagedFile is a temporary file which replaces file on Commit.
+type osStagedFile struct {
+	name string
+	tmp  string
+}
+
+func (f *osStagedFile) Commit() error {
+	if err := os.Rename(f.tmp, f.name); err != nil {
+		_ = os.Remove(f.tmp)
+		return err
+	}
+	return nil
+}
+
+func (f *osStagedFile) Discard() error {
+	if err := os.Remove(f.tmp); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// MemoryOutput keeps generated files in memory. It's safe for concurrent use.
+type MemoryOutput struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemoryOutput returns MemoryOutput with given files as existing ones.
+func NewMemoryOutput(files map[string][]byte) *MemoryOutput {
+	o := &MemoryOutput{files: make(map[string][]byte, len(files))}
+	for name, content := range files {
+		o.files[name] = append([]byte(nil), content...)
+	}
+	return o
+}
+
+func (o *MemoryOutput) ReadFile(name string) ([]byte, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	content, ok := o.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), content...), nil
+}
+
+func (o *MemoryOutput) WriteFile(name string, content []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.files == nil {
+		o.files = make(map[string][]byte)
+	}
+	o.files[name] = append([]byte(nil), content...)
+	return nil
+}
+
+func (o *MemoryOutput) RemoveFile(name string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(o.files, name)
+	return nil
+}
+
+// Files returns copy of all files in output.
+func (o *MemoryOutput) Files() map[string][]byte {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	files := make(map[string][]byte, len(o.files))
+	for name, content := range o.files {
+		files[name] = append([]byte(nil), content...)
+	}
+	return files
+}
@@ -0,0 +1,68 @@
+package simplegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOSOutputStageFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "gen_gen.go")
+	if err := os.WriteFile(name, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := OSOutput{}
+	discarded, err := out.StageFile(name, []byte("discarded"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	committed, err := out.StageFile(name, []byte("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertContent := func(want string, wantFiles int) {
+		t.Helper()
+		content, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != want {
+			t.Errorf("content = %q, want %q", content, want)
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != wantFiles {
+			t.Errorf("dir has %d files, want %d", len(entries), wantFiles)
+		}
+	}
+
+	// staged files don't change file
+	assertContent("old", 3)
+
+	if err = discarded.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	assertContent("old", 2)
+
+	if err = committed.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assertContent("new", 1)
+	if err = committed.Discard(); err != nil {
+		t.Errorf("Discard() after Commit() = %v", err)
+	}
+	assertContent("new", 1)
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o600))
+	}
+}
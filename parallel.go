@@ -0,0 +1,35 @@
+package simplegen
+
+import "sync"
+
+// parallel calls f for every index from 0 to n-1 using at most sg.concurrency goroutines.
+// f is called sequentially if concurrency is not set.
+func (sg *SimpleGenerator) parallel(n int, f func(i int)) {
+	workers := sg.concurrency
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
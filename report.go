@@ -0,0 +1,26 @@
+package simplegen
+
+import "fmt"
+
+// Report describes files touched by the last Generate call.
+type Report struct {
+	// Written contains paths of written files.
+	Written []string
+	// Unchanged contains paths of generated files which content is up to date, they are not rewritten.
+	Unchanged []string
+	// Removed contains paths of removed orphaned files.
+	Removed []string
+	// Orphaned contains paths of orphaned files which were kept, see WithKeepOrphans.
+	Orphaned []string
+}
+
+// String returns summary of report: "2 written, 5 unchanged, 1 removed, 0 orphaned".
+func (r Report) String() string {
+	return fmt.Sprintf("%d written, %d unchanged, %d removed, %d orphaned",
+		len(r.Written), len(r.Unchanged), len(r.Removed), len(r.Orphaned))
+}
+
+// Report returns report of the last Generate call.
+func (sg *SimpleGenerator) Report() Report {
+	return sg.report
+}
@@ -100,14 +100,19 @@ package simplegen
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/format"
 	"go/token"
 	"go/types"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 
 	"golang.org/x/tools/go/packages"
@@ -121,165 +126,687 @@ const packagesLoadMode = packages.NeedName |
 	packages.NeedModule
 
 type SimpleGenerator struct {
+	*generatorState
+
+	// pkg is a package generator funcs are called for, it's set in copies of generator passed to them
+	pkg *packages.Package
+	// targets contains import tables of other target packages private to pkg
+	targets map[*packages.Package]*importTable
+}
+
+// generatorState is shared by SimpleGenerator and its copies passed to generator funcs.
+type generatorState struct {
 	// pkgs collects all used packages for easy use
-	pkgs map[pkgPath]*packages.Package
+	pkgs   map[pkgPath]*packages.Package
+	pkgsMu sync.Mutex
+	// roots are packages where simplegen looks for magic comments, sorted by path
+	roots []*packages.Package
+	// fset is shared by all loaded packages
+	fset *token.FileSet
+	// dir is a directory packages are loaded from
+	dir string
 
 	generators GeneratorsMap
-	cmdData    map[GeneratorName]map[*packages.Package]*cmdData
+	// templates are parsed templates of generators
+	templates map[GeneratorName]*template.Template
+	cmdData   map[GeneratorName]map[*packages.Package]*cmdData
+	// args contains parsed arguments of magic comments
+	args map[*ast.Comment]Arguments
+	// imports contains names of imported packages for each target package
+	imports map[*packages.Package]*importTable
+	// failed contains generators and packages with annotation errors, their files are left untouched
+	failed map[GeneratorName]map[*packages.Package]bool
+	// mu guards cmdData, args, imports and failed maps
+	mu sync.Mutex
 
 	tmplFuncMap template.FuncMap
+
+	output            Output
+	keepOrphans       bool
+	fixImportsEnabled bool
+	transactional     bool
+	// concurrency is a number of packages processed in parallel
+	concurrency int
+
+	report Report
 }
 
-func NewSimpleGenerator(pkgNames PackageNames, generators GeneratorsMap, tmplFuncMap template.FuncMap) (*SimpleGenerator, error) {
+func NewSimpleGenerator(
+	pkgNames PackageNames,
+	generators GeneratorsMap,
+	tmplFuncMap template.FuncMap,
+	opts ...Option,
+) (*SimpleGenerator, error) {
 	dir, err := os.Getwd()
 	if err != nil {
 		panic(err)
 	}
 
-	fset := token.NewFileSet()
-	cfg := &packages.Config{Fset: fset, Mode: packagesLoadMode, Dir: dir}
+	sg := &SimpleGenerator{generatorState: &generatorState{
+		generators:  generators,
+		templates:   make(map[GeneratorName]*template.Template),
+		pkgs:        make(map[pkgPath]*packages.Package),
+		fset:        token.NewFileSet(),
+		dir:         dir,
+		cmdData:     make(map[GeneratorName]map[*packages.Package]*cmdData),
+		args:        make(map[*ast.Comment]Arguments),
+		imports:     make(map[*packages.Package]*importTable),
+		tmplFuncMap: tmplFuncMap,
+		output:      OSOutput{},
+	}}
+	for _, opt := range opts {
+		opt(sg)
+	}
+
+	// generators are validated before slow loading of packages
+	errors := Errors{}
+	for _, genName := range sortedGeneratorNames(generators) {
+		generator := generators[genName]
+		if generator.annotationFunc() == nil {
+			errors = append(errors, fmt.Errorf("%s: generator func is not set", genName))
+		}
+		if err = generator.validateArgs(); err != nil {
+			errors = append(errors, fmt.Errorf("%s: %w", genName, err))
+		}
+		if err = sg.parseTemplate(genName, generator.Template); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if len(errors) > 0 {
+		return nil, errors
+	}
+
+	cfg := &packages.Config{Fset: sg.fset, Mode: packagesLoadMode, Dir: dir}
 	pkgs, err := packages.Load(cfg,
 		pkgNames...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load packages %s: %w", pkgNames, err)
 	}
+	for _, pkg := range pkgs {
+		sg.pkgs[pkgPath(pkg.PkgPath)] = pkg
+		sg.roots = append(sg.roots, pkg)
+	}
+	sort.Slice(sg.roots, func(i, j int) bool {
+		return sg.roots[i].PkgPath < sg.roots[j].PkgPath
+	})
+
+	return sg, nil
+}
 
-	errors := sgErrors{}
+// Generate finds magic comments in packages and writes generated files to output.
+// Files of generators which failed for package are not changed, the rest is written.
+func (sg *SimpleGenerator) Generate() error {
+	sg.report = Report{}
 
-	sg := &SimpleGenerator{
-		generators:  generators,
-		pkgs:        make(map[pkgPath]*packages.Package),
-		cmdData:     make(map[GeneratorName]map[*packages.Package]*cmdData),
-		tmplFuncMap: tmplFuncMap,
+	collectErr := sg.collect()
+	if collectErr != nil && sg.transactional {
+		return collectErr
 	}
-	for _, pkg := range pkgs {
-		sg.pkgs[pkgPath(pkg.PkgPath)] = pkg
+
+	files, err := sg.render()
+	if sg.transactional {
+		return sg.commit(files, err)
 	}
 
+	errors := Errors{}.add(collectErr).add(err)
+	errors = errors.add(sg.write(files))
+	errors = errors.add(sg.removeOrphans(nil))
+
 	if len(errors) > 0 {
-		return nil, errors
+		return errors
 	}
+	return nil
+}
 
-	return sg, nil
+// commit writes rendered files and removes orphaned ones only if every generator succeeded.
+// If any change of output fails, output is restored to previous state.
+func (sg *SimpleGenerator) commit(files []*generatedFile, renderErr error) error {
+	if renderErr != nil {
+		return renderErr
+	}
+
+	b := newBackup(sg.output)
+	err := sg.writeStaged(files, b)
+	if err == nil {
+		err = sg.removeOrphans(b)
+	}
+	if err == nil {
+		return nil
+	}
+
+	sg.report.Written = nil
+	sg.report.Removed = nil
+	return Errors{}.add(err).add(b.restore())
 }
 
-func (sg *SimpleGenerator) Generate() error {
-	errors := sgErrors{}
-
-	// first, inspect ast of loaded packages to find
-	for _, pkg := range sg.pkgs {
-		for _, fileAst := range pkg.Syntax {
-			ast.Inspect(fileAst, func(n ast.Node) bool {
-				switch node := n.(type) {
-				case *ast.GenDecl:
-					copyGenDeclCommentsToSpecs(node)
-				case *ast.TypeSpec:
-					if node.Doc == nil {
-						return true
-					}
-					for _, comment := range node.Doc.List {
-						if strings.Contains(comment.Text, CmdKey) {
-							for cmd, generator := range sg.generators {
-								if strings.Contains(comment.Text, string(cmd)) {
-									err := sg.add(cmd, pkg, node, comment, generator.GeneratorFunc)
-									if err != nil {
-										errors = append(errors, err)
-									}
-								}
-							}
+// GenerateToMemory finds magic comments in packages and returns content of generated files by their paths.
+// Nothing is written to output. On error returned files contain everything rendered successfully.
+func (sg *SimpleGenerator) GenerateToMemory() (map[string][]byte, error) {
+	collectErr := sg.collect()
+	files, err := sg.render()
+
+	contents := make(map[string][]byte, len(files))
+	for _, file := range files {
+		contents[file.path] = file.content
+	}
+
+	errors := Errors{}.add(collectErr).add(err)
+	if len(errors) > 0 {
+		return contents, errors
+	}
+	return contents, nil
+}
+
+// collect inspects ast of loaded packages to find magic comments and collects template data.
+// Data of generators which failed for package is dropped, so they are not rendered.
+func (sg *SimpleGenerator) collect() error {
+	errors := Errors{}
+
+	sg.cmdData = make(map[GeneratorName]map[*packages.Package]*cmdData)
+	sg.args = make(map[*ast.Comment]Arguments)
+	sg.imports = make(map[*packages.Package]*importTable)
+	sg.failed = make(map[GeneratorName]map[*packages.Package]bool)
+
+	// ast is changed before packages are inspected in parallel,
+	// generator funcs can walk ast of other packages concurrently
+	for _, pkg := range sg.roots {
+		copyPackageComments(pkg)
+	}
+
+	// packages are inspected in parallel, errors are reported in order of packages
+	pkgErrors := make([][]error, len(sg.roots))
+	sg.parallel(len(sg.roots), func(i int) {
+		pkgErrors[i] = sg.collectPackage(sg.roots[i])
+	})
+	for _, errs := range pkgErrors {
+		errors = append(errors, errs...)
+	}
+	for genName, pkgs := range sg.failed {
+		for pkg := range pkgs {
+			delete(sg.cmdData[genName], pkg)
+		}
+	}
+
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+// collectPackage inspects ast of package. Annotations of package are processed sequentially,
+// so specs and import names of package don't depend on concurrency.
+// Package ast is only read, it's safe to collect packages in parallel.
+func (sg *SimpleGenerator) collectPackage(pkg *packages.Package) []error {
+	if pkg.Module == nil {
+		return packageNotFound(pkg)
+	}
+
+	// generator funcs of package get own copy of generator, see forPackage
+	sg = sg.forPackage(pkg)
+
+	var errs []error
+	// only package level declarations are annotated, declarations inside functions are out of scope
+	// of generated code
+	for _, fileAst := range pkg.Syntax {
+		for _, decl := range fileAst.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				errs = append(errs, sg.inspect(pkg, fileAst, decl, decl.Doc, pkg.TypesInfo.Defs[decl.Name])...)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						errs = append(errs, sg.inspect(pkg, fileAst, spec, spec.Doc, pkg.TypesInfo.Defs[spec.Name])...)
+					case *ast.ValueSpec:
+						// const/var spec can declare several names, each of them is a separate object
+						for _, name := range spec.Names {
+							errs = append(errs, sg.inspect(pkg, fileAst, spec, spec.Doc, pkg.TypesInfo.Defs[name])...)
 						}
 					}
 				}
-				return true
-			})
+			}
 		}
 	}
-	if len(errors) > 0 {
-		return errors
+	return errs
+}
+
+// inspect looks for magic comments in node docs and calls matched generators.
+func (sg *SimpleGenerator) inspect(
+	pkg *packages.Package,
+	file *ast.File,
+	node ast.Node,
+	doc *ast.CommentGroup,
+	obj types.Object,
+) []error {
+	if doc == nil {
+		return nil
 	}
 
-	// second, write collected specs to files
-	return sg.write()
+	var errs []error
+	for _, comment := range doc.List {
+		directive, ok := ParseDirective(comment.Text)
+		if !ok {
+			continue
+		}
+		generator, ok := sg.generators[directive.Generator]
+		if !ok {
+			continue
+		}
+
+		annotation := newAnnotation(sg, pkg, file, node, doc, obj, comment, directive)
+		if len(generator.Args) > 0 {
+			args, err := generator.ParseArgs(directive.Raw)
+			if err != nil {
+				sg.markFailed(directive.Generator, pkg)
+				errs = append(errs, &AnnotationError{
+					Generator: directive.Generator,
+					Pos:       sg.Position(comment),
+					TypeName:  annotation.Name(),
+					Err:       err,
+				})
+				continue
+			}
+			annotation.Args = args
+			sg.mu.Lock()
+			sg.args[comment] = args
+			sg.mu.Unlock()
+		}
+
+		templateData, imports, err := generator.annotationFunc()(sg, annotation)
+		if errors.Is(err, ErrSkip) {
+			continue
+		}
+		if err == nil {
+			imports = append(imports, annotation.imports...)
+			err = sg.add(annotation, templateData, imports)
+		}
+		if err != nil {
+			sg.markFailed(directive.Generator, pkg)
+			errs = append(errs, annotationError(annotation, err))
+		}
+	}
+	return errs
+}
+
+// packageNotFound returns load errors of package which is not found in any module.
+// There is nowhere to write its generated files.
+func packageNotFound(pkg *packages.Package) []error {
+	errs := make([]error, 0, len(pkg.Errors))
+	for _, err := range pkg.Errors {
+		errs = append(errs, fmt.Errorf("cannot load package %s: %w", pkg.PkgPath, err))
+	}
+	if len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("cannot load package %s: package is not in a module", pkg.PkgPath))
+	}
+	return errs
+}
+
+// markFailed marks generator as failed for package, file of the generator is left untouched.
+func (sg *SimpleGenerator) markFailed(genName GeneratorName, pkg *packages.Package) {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+
+	if _, ok := sg.failed[genName]; !ok {
+		sg.failed[genName] = make(map[*packages.Package]bool)
+	}
+	sg.failed[genName][pkg] = true
 }
 
 func (sg *SimpleGenerator) add(
-	genName GeneratorName,
-	pkg *packages.Package,
-	node *ast.TypeSpec,
-	comment *ast.Comment,
-	genFunc GeneratorFunc,
+	annotation *Annotation,
+	templateData SpecData,
+	imports []Import,
 ) error {
+	genName, pkg := annotation.Generator, annotation.Package
+	table := sg.importTable(pkg)
+
+	sg.mu.Lock()
 	if _, ok := sg.cmdData[genName]; !ok {
 		sg.cmdData[genName] = make(map[*packages.Package]*cmdData)
 	}
 
-	templateData, rawImports, err := genFunc(sg, pkg, node, comment)
-	if err != nil {
-		return err
+	_, ok := sg.cmdData[genName][pkg]
+	if !ok {
+		sg.cmdData[genName][pkg] = newGeneratorData(pkg.Name, table)
 	}
 
-	importsMap := map[string]struct{}{}
-	var imports []string
-	for _, rawImp := range rawImports {
-		if _, ok := importsMap[rawImp]; !ok {
-			importsMap[rawImp] = struct{}{}
-			imports = append(imports, rawImp)
+	// data of package is changed only by goroutine which collects package
+	data := sg.cmdData[genName][pkg]
+	sg.mu.Unlock()
+
+	for _, imp := range imports {
+		if _, err := data.addImport(imp, sg.packageName(imp.Path)); err != nil {
+			return err
 		}
 	}
+	data.add(templateData, annotation)
+	return nil
+}
 
-	_, ok := sg.cmdData[genName][pkg]
-	if !ok {
-		sg.cmdData[genName][pkg] = newGeneratorData(pkg.Name, imports)
+// generatedFile is a rendered file of generator for package.
+type generatedFile struct {
+	path      string
+	generator GeneratorName
+	pkg       *packages.Package
+	content   []byte
+}
+
+// render executes templates for collected data and returns formatted generated files sorted by path.
+// Every generator and package is rendered, files which failed to execute or format are not returned.
+func (sg *SimpleGenerator) render() ([]*generatedFile, error) {
+	genNames := sortedGeneratorNames(sg.cmdData)
+
+	type result struct {
+		file *generatedFile
+		err  error
 	}
+	// results[i][j] is a result of generator genNames[j] for package sg.roots[i]
+	results := make([][]result, len(sg.roots))
+	sg.parallel(len(sg.roots), func(i int) {
+		pkg := sg.roots[i]
+		results[i] = make([]result, len(genNames))
+		// generators of package share import names, so they are rendered sequentially
+		for j, genName := range genNames {
+			if data, ok := sg.cmdData[genName][pkg]; ok {
+				results[i][j].file, results[i][j].err = sg.renderGenerator(genName, pkg, data)
+			}
+		}
+	})
+
+	errors := Errors{}
+	var files []*generatedFile
+	for j := range genNames {
+		for i := range sg.roots {
+			if err := results[i][j].err; err != nil {
+				errors = append(errors, err)
+			}
+			if file := results[i][j].file; file != nil {
+				files = append(files, file)
+			}
+		}
+	}
+	if len(errors) > 0 {
+		return sortedFiles(files), errors
+	}
+	return sortedFiles(files), nil
+}
+
+// renderGenerator executes generator template for package data and formats the result.
+func (sg *SimpleGenerator) renderGenerator(
+	genName GeneratorName,
+	pkg *packages.Package,
+	data *cmdData,
+) (*generatedFile, error) {
+	tmpl := sg.templates[genName]
 
-	sg.cmdData[genName][pkg].add(templateData)
+	content, err := sg.renderFile(tmpl, data)
+	if err != nil {
+		templateErr := &TemplateError{
+			Generator: genName,
+			PkgPath:   pkg.PkgPath,
+			Line:      templateErrorLine(err),
+			Err:       err,
+		}
+		if a := sg.failedSpec(tmpl, data); a != nil {
+			templateErr.TypeName = a.Name()
+			templateErr.Pos = a.Pos
+		}
+		return nil, templateErr
+	}
+
+	fileName := generatedFilePath(pkg, genName)
+	raw := content
+	if sg.fixImportsEnabled {
+		content, err = sg.fixImports(fileName, pkg, raw)
+	} else {
+		content, err = format.Source(raw)
+	}
+	if err != nil {
+		return nil, sg.formatError(genName, fileName, data, raw, err)
+	}
+
+	return &generatedFile{
+		path:      fileName,
+		generator: genName,
+		pkg:       pkg,
+		content:   content,
+	}, nil
+}
+
+// failedSpec executes template for every spec separately and returns annotation of the first failed one.
+// Returns nil if template fails only for specs together.
+func (sg *SimpleGenerator) failedSpec(tmpl *template.Template, data *cmdData) *Annotation {
+	if len(data.Specs) < 2 {
+		if len(data.annotations) == 1 {
+			return data.annotations[0]
+		}
+		return nil
+	}
+	for i := range data.Specs {
+		if _, err := sg.renderFile(tmpl, data.single(i)); err != nil {
+			return data.annotations[i]
+		}
+	}
 	return nil
 }
 
-func (sg *SimpleGenerator) write() error {
-	errors := sgErrors{}
+// parseTemplate parses generator template with all template funcs.
+func (sg *SimpleGenerator) parseTemplate(genName GeneratorName, text string) error {
+	tmpl, err := sg.newTemplate(genName, text)
+	if err != nil {
+		return &TemplateError{Generator: genName, Line: templateErrorLine(err), Err: err}
+	}
+	sg.templates[genName] = tmpl
+	return nil
+}
 
-	for genName, genData := range sg.cmdData {
-		cmdTemplate := sg.generators[genName].Template
+// renderFile executes template for file data.
+// Header is executed after template, so imports added by template funcs get into it.
+func (sg *SimpleGenerator) renderFile(tmpl *template.Template, specs *cmdData) ([]byte, error) {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(specs.fileFuncs(sg.packageName))
 
-		templateRaw := header + cmdTemplate
-		tmpl := template.Must(template.New("").Funcs(sg.tmplFuncMap).Parse(templateRaw))
+	body := bytes.Buffer{}
+	if err = tmpl.Execute(&body, specs); err != nil {
+		return nil, err
+	}
 
-		for pkg, specs := range genData {
-			buf := bytes.Buffer{}
+	specs.groupImports(sg.isStdLib)
 
-			if err := tmpl.Execute(&buf, specs); err != nil {
-				return err
-			}
+	buf := bytes.Buffer{}
+	if err = headerTemplate.Execute(&buf, specs); err != nil {
+		return nil, err
+	}
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
 
-			content, err := format.Source(buf.Bytes())
-			if err != nil {
-				errors = append(errors, err)
-				continue
-			}
+// write writes rendered files to output.
+// Existing files are overwritten only if they were generated by simplegen.
+// Files with the same content are not written.
+func (sg *SimpleGenerator) write(files []*generatedFile) error {
+	changed, errors := sg.changedFiles(files)
 
-			pkgDir := filepath.Join(pkg.Module.Dir, strings.TrimPrefix(pkg.PkgPath, pkg.Module.Path))
+	for _, file := range changed {
+		if err := sg.output.WriteFile(file.path, file.content); err != nil {
+			errors = append(errors, &WriteError{Generator: file.generator, Path: file.path, Err: err})
+			continue
+		}
+		sg.report.Written = append(sg.report.Written, file.path)
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
 
-			fName := fmt.Sprintf("%s_gen.go", genName)
+// writeStaged writes rendered files in two passes: every file is staged first
+// and files are replaced only when all of them are staged. Nothing is changed if any file can't be staged.
+// Previous content of replaced files is saved to backup.
+func (sg *SimpleGenerator) writeStaged(files []*generatedFile, b *backup) error {
+	changed, errors := sg.changedFiles(files)
+	if len(errors) > 0 {
+		return errors
+	}
 
-			err = writeFile(filepath.Join(pkgDir, fName), content)
-			if err != nil {
-				errors = append(errors, err)
-			}
+	staged := make([]StagedFile, 0, len(changed))
+	discard := func(staged []StagedFile) {
+		for _, s := range staged {
+			_ = s.Discard()
+		}
+	}
+	for _, file := range changed {
+		s, err := stageFile(sg.output, file.path, file.content)
+		if err != nil {
+			discard(staged)
+			return &WriteError{Generator: file.generator, Path: file.path, Err: err}
+		}
+		staged = append(staged, s)
+	}
+
+	for i, file := range changed {
+		err := b.save(file.path)
+		if err == nil {
+			err = staged[i].Commit()
+		}
+		if err != nil {
+			discard(staged[i:])
+			return &WriteError{Generator: file.generator, Path: file.path, Err: err}
+		}
+		sg.report.Written = append(sg.report.Written, file.path)
+	}
+	return nil
+}
+
+// changedFiles returns files which content differs from existing files in output,
+// unchanged files are reported. Files which can't be overwritten are returned as errors.
+func (sg *SimpleGenerator) changedFiles(files []*generatedFile) ([]*generatedFile, Errors) {
+	var changed []*generatedFile
+	errors := Errors{}
+
+	for _, file := range files {
+		existing, err := sg.checkOverwrite(file)
+		if err != nil {
+			errors = append(errors, err)
+			continue
+		}
+		if existing != nil && bytes.Equal(existing, file.content) {
+			// keep mtime of file untouched
+			sg.report.Unchanged = append(sg.report.Unchanged, file.path)
+			continue
+		}
+		changed = append(changed, file)
+	}
+	return changed, errors
+}
+
+// checkOverwrite returns error if file exists and it's not generated by simplegen.
+// Returns content of existing file, nil if file doesn't exist.
+func (sg *SimpleGenerator) checkOverwrite(file *generatedFile) ([]byte, error) {
+	existing, err := sg.output.ReadFile(file.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, &WriteError{Generator: file.generator, Path: file.path, Err: err}
+	}
+	if !isGenerated(existing) {
+		return nil, &WriteError{
+			Generator: file.generator,
+			Path:      file.path,
+			Err:       fmt.Errorf("refusing to overwrite: %w", ErrNotGenerated),
 		}
 	}
+	return existing, nil
+}
+
+// removeOrphans removes generated files which have nothing to generate anymore.
+// Removed files are saved to backup if it's not nil.
+func (sg *SimpleGenerator) removeOrphans(b *backup) error {
+	orphans, err := sg.orphanedFiles()
+	if err != nil {
+		return err
+	}
+
+	errors := Errors{}
+	for _, fileName := range sortedKeys(orphans) {
+		if sg.keepOrphans {
+			sg.report.Orphaned = append(sg.report.Orphaned, fileName)
+			continue
+		}
+		if err = b.save(fileName); err == nil {
+			err = sg.output.RemoveFile(fileName)
+		}
+		if err != nil {
+			errors = append(errors, &WriteError{Path: fileName, Err: fmt.Errorf("cannot remove orphaned file: %w", err)})
+			continue
+		}
+		sg.report.Removed = append(sg.report.Removed, fileName)
+	}
 	if len(errors) > 0 {
 		return errors
 	}
 	return nil
 }
 
+// orphanedFiles returns content of generated files of registered generators in scanned packages
+// which have nothing to generate. Files of failed generators are not orphaned.
+func (sg *SimpleGenerator) orphanedFiles() (map[string][]byte, error) {
+	orphans := make(map[string][]byte)
+
+	for _, pkg := range sg.roots {
+		if pkg.Module == nil {
+			// package is not found, collect reports it
+			continue
+		}
+		for _, genName := range sortedGeneratorNames(sg.generators) {
+			if _, ok := sg.cmdData[genName][pkg]; ok || sg.failed[genName][pkg] {
+				continue
+			}
+
+			fileName := generatedFilePath(pkg, genName)
+			content, err := sg.output.ReadFile(fileName)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if isGenerated(content) {
+				orphans[fileName] = content
+			}
+		}
+	}
+	return orphans, nil
+}
+
+// Args returns parsed arguments of magic comment.
+// Arguments are available only for generators with declared TemplateGenerator.Args.
+func (sg *SimpleGenerator) Args(comment *ast.Comment) Arguments {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+
+	return sg.args[comment]
+}
+
+// PrintUsage writes help for every registered generator and its arguments.
+func (sg *SimpleGenerator) PrintUsage(w io.Writer) {
+	sg.generators.PrintUsage(w)
+}
+
 // GetPackage returns packages.Package. It tries to load package if it didn't load before.
+// It's safe for concurrent use.
 func (sg *SimpleGenerator) GetPackage(path string) (*packages.Package, error) {
+	sg.pkgsMu.Lock()
+	defer sg.pkgsMu.Unlock()
+
 	pkg, ok := sg.pkgs[pkgPath(path)]
 	if !ok {
-		pkgs, err := packages.Load(&packages.Config{Mode: packagesLoadMode}, path)
+		pkgs, err := packages.Load(&packages.Config{Fset: sg.fset, Mode: packagesLoadMode, Dir: sg.dir}, path)
 		if err != nil {
 			return nil, err
 		}
@@ -292,6 +819,17 @@ func (sg *SimpleGenerator) GetPackage(path string) (*packages.Package, error) {
 	return pkg, nil
 }
 
+// Fset returns file set shared by all packages loaded by simplegen, including ones loaded by GetPackage.
+func (sg *SimpleGenerator) Fset() *token.FileSet {
+	return sg.fset
+}
+
+// Position returns file:line:column of ast.Node, types.Object or anything else with position
+// in loaded packages. Returns invalid position for nodes from other file sets.
+func (sg *SimpleGenerator) Position(node interface{ Pos() token.Pos }) token.Position {
+	return sg.fset.Position(node.Pos())
+}
+
 // GetObject tries to find type object in given package.
 // In most cases you don't need it, use GetStructType instead.
 func (sg *SimpleGenerator) GetObject(pkg *packages.Package, typeName string) (types.Object, error) {
@@ -324,16 +862,64 @@ func (sg *SimpleGenerator) GetStructType(pkg *packages.Package, typeName string)
 	return structType, nil
 }
 
-// writeFile (re)creates a new file and writes content into it.
-func writeFile(fileName string, fileContent []byte) error {
-	f, err := os.Create(fileName)
-	if err != nil {
-		return err
+// generatedFilePath returns path of file generated by generator in package dir.
+// Package must belong to a module.
+func generatedFilePath(pkg *packages.Package, genName GeneratorName) string {
+	pkgDir := filepath.Join(pkg.Module.Dir, strings.TrimPrefix(pkg.PkgPath, pkg.Module.Path))
+	return filepath.Join(pkgDir, fmt.Sprintf("%s_gen.go", genName))
+}
+
+// isGenerated reports if file content starts with simplegen header.
+func isGenerated(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == generatedComment {
+			return true
+		}
+		if line != "" && !strings.HasPrefix(line, "//") {
+			// header should be before package clause
+			return false
+		}
 	}
-	defer f.Close()
+	return false
+}
+
+func sortedGeneratorNames[T any](m map[GeneratorName]T) []GeneratorName {
+	names := make([]GeneratorName, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
 
-	_, err = f.Write(fileContent)
-	return err
+func sortedFiles(files []*generatedFile) []*generatedFile {
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	return files
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// copyPackageComments copies doc comments of package level declarations to their specs in every file of package.
+func copyPackageComments(pkg *packages.Package) {
+	for _, fileAst := range pkg.Syntax {
+		for _, decl := range fileAst.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok {
+				copyGenDeclCommentsToSpecs(decl)
+			}
+		}
+	}
 }
 
 // copyDocsToSpecs will take the GenDecl level documents and copy them
@@ -344,7 +930,12 @@ func copyGenDeclCommentsToSpecs(x *ast.GenDecl) {
 	// cause they missed this... whoops
 	if x.Doc != nil {
 		for _, spec := range x.Specs {
-			if s, ok := spec.(*ast.TypeSpec); ok {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Doc == nil {
+					s.Doc = x.Doc
+				}
+			case *ast.ValueSpec:
 				if s.Doc == nil {
 					s.Doc = x.Doc
 				}
@@ -0,0 +1,225 @@
+package simplegen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// snippetContext is a number of lines shown around error line in FormatError.
+const snippetContext = 3
+
+// Line markers are inserted into template text nodes to find template line which produced generated line.
+const (
+	lineMarkerStart = '\x00'
+	lineMarkerEnd   = '\x01'
+)
+
+// formatError builds FormatError with raw source of generated file, mapped back to template lines.
+func (sg *SimpleGenerator) formatError(
+	genName GeneratorName,
+	fileName string,
+	data *cmdData,
+	src []byte,
+	err error,
+) *FormatError {
+	formatErr := &FormatError{
+		Generator: genName,
+		Path:      fileName,
+		Source:    src,
+		Err:       err,
+	}
+
+	errLines := make(map[int]bool)
+	var list scanner.ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			errLines[e.Pos.Line] = true
+		}
+		if len(list) > 0 {
+			formatErr.Line = list[0].Pos.Line
+		}
+	}
+	if formatErr.Line == 0 {
+		return formatErr
+	}
+
+	templateLines := sg.templateLines(genName, data, src)
+	if len(templateLines) >= formatErr.Line {
+		formatErr.TemplateLine = templateLines[formatErr.Line-1]
+	}
+	formatErr.Snippet = snippet(src, formatErr.Line, errLines, templateLines)
+	return formatErr
+}
+
+// templateLines returns template line for every line of generated source, 0 for lines of header.
+// Template is executed again with line markers. Returns nil if lines can't be mapped.
+func (sg *SimpleGenerator) templateLines(genName GeneratorName, data *cmdData, src []byte) []int {
+	tmpl, err := sg.newTemplate(genName, sg.generators[genName].Template)
+	if err != nil {
+		return nil
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			instrument(t.Tree, t.Tree.Root)
+		}
+	}
+
+	marked, err := sg.renderFile(tmpl, data)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		lines   []int
+		current int
+		plain   bytes.Buffer
+	)
+	for _, line := range bytes.Split(marked, []byte("\n")) {
+		// line belongs to the last marker before its first rendered byte,
+		// several markers meet at the start of line, like in every iteration of range
+		recorded := false
+		for {
+			start := bytes.IndexByte(line, lineMarkerStart)
+			if start < 0 {
+				break
+			}
+			if start > 0 && !recorded {
+				lines = append(lines, current)
+				recorded = true
+			}
+			end := bytes.IndexByte(line[start:], lineMarkerEnd)
+			if end < 0 {
+				return nil
+			}
+			current = markerLine(line[start:])
+			line = append(line[:start:start], line[start+end+1:]...)
+		}
+		if !recorded {
+			lines = append(lines, current)
+		}
+		plain.Write(line)
+		plain.WriteByte('\n')
+	}
+
+	// template must render the same source with markers removed
+	if !bytes.Equal(bytes.TrimSuffix(plain.Bytes(), []byte("\n")), src) {
+		return nil
+	}
+	return lines
+}
+
+// instrument inserts line markers into text nodes: at the start of node and after every new line.
+// Other nodes get marker before them, so output of action at the start of line is mapped too.
+func instrument(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		nodes := make([]parse.Node, 0, len(n.Nodes))
+		for _, child := range n.Nodes {
+			if _, ok := child.(*parse.TextNode); !ok {
+				if line := nodeLine(tree, child); line > 0 {
+					nodes = append(nodes, &parse.TextNode{NodeType: parse.NodeText, Pos: child.Position(), Text: lineMarker(line)})
+				}
+			}
+			instrument(tree, child)
+			nodes = append(nodes, child)
+		}
+		n.Nodes = nodes
+	case *parse.TextNode:
+		line := nodeLine(tree, n)
+		if line == 0 {
+			return
+		}
+		var buf bytes.Buffer
+		buf.Write(lineMarker(line))
+		for _, b := range n.Text {
+			buf.WriteByte(b)
+			if b == '\n' {
+				line++
+				buf.Write(lineMarker(line))
+			}
+		}
+		n.Text = buf.Bytes()
+	case *parse.IfNode:
+		instrument(tree, n.List)
+		instrument(tree, n.ElseList)
+	case *parse.RangeNode:
+		instrument(tree, n.List)
+		instrument(tree, n.ElseList)
+	case *parse.WithNode:
+		instrument(tree, n.List)
+		instrument(tree, n.ElseList)
+	}
+}
+
+// nodeLine returns template line of node, 0 if unknown.
+func nodeLine(tree *parse.Tree, node parse.Node) int {
+	location, _ := tree.ErrorContext(node)
+	// location is "name:line:col"
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	return line
+}
+
+func lineMarker(line int) []byte {
+	return []byte(string(lineMarkerStart) + strconv.Itoa(line) + string(lineMarkerEnd))
+}
+
+// markerLine parses line from marker at the start of b.
+func markerLine(b []byte) int {
+	end := bytes.IndexByte(b, lineMarkerEnd)
+	if end < 0 {
+		return 0
+	}
+	line, _ := strconv.Atoi(string(b[1:end]))
+	return line
+}
+
+// snippet returns lines of source around error line, error lines are highlighted with ">"
+// and template lines which produced them.
+func snippet(src []byte, errLine int, errLines map[int]bool, templateLines []int) string {
+	lines := strings.Split(string(src), "\n")
+	from := errLine - snippetContext
+	if from < 1 {
+		from = 1
+	}
+	to := errLine + snippetContext
+	if to > len(lines) {
+		to = len(lines)
+	}
+
+	width := len(strconv.Itoa(to))
+	b := strings.Builder{}
+	for i := from; i <= to; i++ {
+		if !errLines[i] {
+			fmt.Fprintf(&b, "  %*d | %s\n", width, i, lines[i-1])
+			continue
+		}
+		fmt.Fprintf(&b, "> %*d | %s", width, i, lines[i-1])
+		if i <= len(templateLines) && templateLines[i-1] > 0 {
+			fmt.Fprintf(&b, "    <- template line %d", templateLines[i-1])
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// newTemplate parses generator template with all template funcs.
+func (sg *SimpleGenerator) newTemplate(genName GeneratorName, text string) (*template.Template, error) {
+	return template.New(string(genName)).
+		Funcs(renderFuncs).
+		Funcs(TagFuncs).
+		Funcs(sg.tmplFuncMap).
+		Parse(text)
+}
@@ -0,0 +1,85 @@
+package simplegen
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTemplateLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		specs    []SpecData
+		// want are template lines of generated lines after header
+		want []int
+	}{
+		{
+			name:     "one line range",
+			template: "{{ range .Specs }}var _ = {{ . }}\n{{ end }}",
+			specs:    []SpecData{"a", "b", "c"},
+			want:     []int{1, 1, 1, 2},
+		},
+		{
+			name:     "multiline range",
+			template: "// start\n{{ range .Specs }}\nvar {{ . }} int\n{{ end }}\n// end",
+			specs:    []SpecData{"a", "b"},
+			want:     []int{1, 2, 3, 2, 3, 4, 5},
+		},
+		{
+			name:     "action at line start",
+			template: "{{ range .Specs }}{{ . }} := 1\n{{ end }}",
+			specs:    []SpecData{"a", "b"},
+			want:     []int{1, 1, 2},
+		},
+		{
+			name:     "if",
+			template: "// start\n{{ if .Specs }}\n// yes\n{{ else }}\n// no\n{{ end }}\n// end",
+			specs:    []SpecData{"a"},
+			want:     []int{1, 2, 3, 6, 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sg := &SimpleGenerator{generatorState: &generatorState{generators: GeneratorsMap{"gen": {Template: tt.template}}}}
+			newData := func() *cmdData {
+				data := newGeneratorData("target", newImportTable(newTestTarget()))
+				for _, spec := range tt.specs {
+					data.add(spec, nil)
+				}
+				return data
+			}
+
+			tmpl, err := sg.newTemplate("gen", tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			src, err := sg.renderFile(tmpl, newData())
+			if err != nil {
+				t.Fatal(err)
+			}
+			empty, err := sg.newTemplate("gen", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			header, err := sg.renderFile(empty, newData())
+			if err != nil {
+				t.Fatal(err)
+			}
+			headerLines := bytes.Count(header, []byte("\n"))
+
+			lines := sg.templateLines("gen", newData(), src)
+			if lines == nil {
+				t.Fatalf("templateLines() = nil for\n%s", src)
+			}
+			for i, line := range lines[:headerLines] {
+				if line != 0 {
+					t.Errorf("header line %d has template line %d", i+1, line)
+				}
+			}
+			if got := lines[headerLines:]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("templateLines() = %v, want %v for\n%s", got, tt.want, src)
+			}
+		})
+	}
+}
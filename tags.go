@@ -0,0 +1,146 @@
+package simplegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Tag is a single key:"value" pair of struct tag.
+type Tag struct {
+	Key string
+	// Value is an unquoted tag value, `json:"name,omitempty"` -> "name,omitempty".
+	Value string
+	// Name is a part of value before first comma, `json:"name,omitempty"` -> "name".
+	Name string
+	// Options are parts of value after name, `json:"name,omitempty"` -> ["omitempty"].
+	Options []string
+}
+
+// HasOption reports if tag has option, `json:",omitempty"` has "omitempty" option.
+func (t Tag) HasOption(option string) bool {
+	for _, o := range t.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags are struct tag pairs in declaration order.
+type Tags []Tag
+
+// ParseTag parses struct tag by reflect.StructTag conventions,
+// `json:"id,omitempty" db:"id" validate:"required,min=1"`.
+// Unlike reflect.StructTag.Get it reports malformed tags.
+func ParseTag(tag string) (Tags, error) {
+	var tags Tags
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			return tags, nil
+		}
+
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return nil, fmt.Errorf("bad syntax for struct tag %q", tag)
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		// scan quoted value, escaped quotes are allowed
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, fmt.Errorf("bad syntax for struct tag value of %s", key)
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, fmt.Errorf("bad syntax for struct tag value of %s: %w", key, err)
+		}
+		tag = tag[i+1:]
+
+		t := Tag{Key: key, Value: value}
+		parts := strings.Split(value, ",")
+		t.Name = parts[0]
+		if len(parts) > 1 {
+			t.Options = parts[1:]
+		}
+		tags = append(tags, t)
+	}
+}
+
+// Get returns first tag with key.
+func (t Tags) Get(key string) (Tag, bool) {
+	for _, tag := range t {
+		if tag.Key == key {
+			return tag, true
+		}
+	}
+	return Tag{}, false
+}
+
+// Keys returns tag keys in declaration order.
+func (t Tags) Keys() []string {
+	keys := make([]string, 0, len(t))
+	for _, tag := range t {
+		keys = append(keys, tag.Key)
+	}
+	return keys
+}
+
+// Name returns tag name of the first key which has it. Ignored names ("-") are skipped.
+//
+//	`yaml:"phoneYaml" json:"phone"`, Name("json", "yaml") -> "phone"
+//	`json:"-" yaml:"phoneYaml"`, Name("json", "yaml") -> "phoneYaml"
+//	`db:"phone"`, Name("json", "yaml") -> ""
+func (t Tags) Name(keys ...string) string {
+	for _, key := range keys {
+		if tag, ok := t.Get(key); ok && tag.Name != "" && tag.Name != "-" {
+			return tag.Name
+		}
+	}
+	return ""
+}
+
+// String returns tags in struct tag format.
+func (t Tags) String() string {
+	parts := make([]string, 0, len(t))
+	for _, tag := range t {
+		parts = append(parts, tag.Key+":"+strconv.Quote(tag.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// TagFuncs are template functions for struct tags, available in every template.
+//
+//	{{ parseTag $tag }} -> Tags
+//	{{ tagValue $tag "db" }} -> "id,pk"
+//	{{ tagName $tag "json" "yaml" }} -> "id"
+var TagFuncs = template.FuncMap{
+	"parseTag": ParseTag,
+	"tagValue": func(tag, key string) (string, error) {
+		tags, err := ParseTag(tag)
+		if err != nil {
+			return "", err
+		}
+		t, _ := tags.Get(key)
+		return t.Value, nil
+	},
+	"tagName": func(tag string, keys ...string) (string, error) {
+		tags, err := ParseTag(tag)
+		if err != nil {
+			return "", err
+		}
+		return tags.Name(keys...), nil
+	},
+}
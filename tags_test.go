@@ -0,0 +1,152 @@
+package simplegen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		want    Tags
+		wantErr bool
+	}{
+		{name: "empty", tag: "", want: nil},
+		{name: "spaces only", tag: "  ", want: nil},
+		{
+			name: "single",
+			tag:  `json:"id"`,
+			want: Tags{{Key: "json", Value: "id", Name: "id"}},
+		},
+		{
+			name: "options",
+			tag:  `json:"id,omitempty,string"`,
+			want: Tags{{Key: "json", Value: "id,omitempty,string", Name: "id", Options: []string{"omitempty", "string"}}},
+		},
+		{
+			name: "options without name",
+			tag:  `json:",omitempty"`,
+			want: Tags{{Key: "json", Value: ",omitempty", Name: "", Options: []string{"omitempty"}}},
+		},
+		{
+			name: "keys keep order",
+			tag:  `yaml:"b"  json:"a" db:"c"`,
+			want: Tags{
+				{Key: "yaml", Value: "b", Name: "b"},
+				{Key: "json", Value: "a", Name: "a"},
+				{Key: "db", Value: "c", Name: "c"},
+			},
+		},
+		{
+			name: "key with json suffix",
+			tag:  `xjson:"x" json:"y"`,
+			want: Tags{
+				{Key: "xjson", Value: "x", Name: "x"},
+				{Key: "json", Value: "y", Name: "y"},
+			},
+		},
+		{
+			name: "escaped quotes",
+			tag:  `validate:"eq=\"a b\"" json:"id"`,
+			want: Tags{
+				{Key: "validate", Value: `eq="a b"`, Name: `eq="a b"`},
+				{Key: "json", Value: "id", Name: "id"},
+			},
+		},
+		{
+			name: "empty value",
+			tag:  `json:""`,
+			want: Tags{{Key: "json", Value: "", Name: ""}},
+		},
+		{name: "no value", tag: `json`, wantErr: true},
+		{name: "no quotes", tag: `json:id`, wantErr: true},
+		{name: "space after colon", tag: `json: "id"`, wantErr: true},
+		{name: "empty key", tag: `:"id"`, wantErr: true},
+		{name: "unterminated value", tag: `json:"id`, wantErr: true},
+		{name: "unterminated after escape", tag: `json:"id\"`, wantErr: true},
+		{name: "invalid escape", tag: `json:"\q"`, wantErr: true},
+		{name: "garbage after pair", tag: `json:"id" db`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTag(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTag(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTag(%q) = %#v, want %#v", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTagMatchesReflect(t *testing.T) {
+	tag := `json:"id,omitempty" xjson:"x" validate:"eq=\"a\"" db:""`
+	tags, err := ParseTag(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"json", "xjson", "validate", "db", "yaml"} {
+		want, wantOk := reflect.StructTag(tag).Lookup(key)
+		got, ok := tags.Get(key)
+		if ok != wantOk || got.Value != want {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", key, got.Value, ok, want, wantOk)
+		}
+	}
+}
+
+func TestTagsName(t *testing.T) {
+	tests := []struct {
+		tag  string
+		keys []string
+		want string
+	}{
+		{tag: `yaml:"phoneYaml" json:"phone"`, keys: []string{"json", "yaml"}, want: "phone"},
+		{tag: `json:"-" yaml:"phoneYaml"`, keys: []string{"json", "yaml"}, want: "phoneYaml"},
+		{tag: `json:",omitempty" yaml:"phoneYaml"`, keys: []string{"json", "yaml"}, want: "phoneYaml"},
+		{tag: `json:"-,"`, keys: []string{"json"}, want: ""},
+		{tag: `db:"phone"`, keys: []string{"json", "yaml"}, want: ""},
+		{tag: `xjson:"phone"`, keys: []string{"json"}, want: ""},
+		{tag: `json:"phone"`, keys: nil, want: ""},
+	}
+	for _, tt := range tests {
+		tags, err := ParseTag(tt.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tags.Name(tt.keys...); got != tt.want {
+			t.Errorf("ParseTag(%q).Name(%q) = %q, want %q", tt.tag, tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestTagsString(t *testing.T) {
+	for _, tag := range []string{
+		``,
+		`json:"id"`,
+		`json:"id,omitempty" db:"id" validate:"required,min=1"`,
+		`validate:"eq=\"a b\""`,
+	} {
+		tags, err := ParseTag(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tags.String(); got != tag {
+			t.Errorf("ParseTag(%q).String() = %q", tag, got)
+		}
+	}
+}
+
+func TestTagHasOption(t *testing.T) {
+	tags, err := ParseTag(`json:"id,omitempty,string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, _ := tags.Get("json")
+	for option, want := range map[string]bool{"omitempty": true, "string": true, "id": false, "": false} {
+		if got := tag.HasOption(option); got != want {
+			t.Errorf("HasOption(%q) = %v, want %v", option, got, want)
+		}
+	}
+}
@@ -1,14 +1,67 @@
 package simplegen
 
-const header = `// Code generated by github.com/AlwxSin/simplegen, DO NOT EDIT.
+import (
+	"fmt"
+	"go/types"
+	"text/template"
+)
+
+// generatedComment marks files generated by simplegen.
+const generatedComment = "// Code generated by github.com/AlwxSin/simplegen, DO NOT EDIT."
+
+const header = generatedComment + `
 package {{.PackageName}}
 
 {{ if ne (len .Imports) 0 }}
 import (
-	{{- range $index, $value := .Imports }}
-	"{{$value}}"
+	{{- range $index, $group := .ImportGroups }}
+	{{- if $index }}
+{{ end }}
+	{{- range $group }}
+	{{ if .Alias }}{{ .Alias }} {{ end }}"{{ .Path }}"
 	{{- end -}}
+	{{- end }}
 )
 {{end}}
 
 `
+
+var headerTemplate = template.Must(template.New("header").Parse(header))
+
+// renderFuncs are available in every template. Placeholders are replaced for each rendered file.
+//
+//	{{ import "time" }}.Duration -> time.Duration, "time" is added to imports
+//	{{ import "github.com/my_project/models" "m" }}.User -> m.User
+//	{{ ref "github.com/my_project/models" "User" }} -> models.User, or User inside models package
+//	{{ qualify $type }} -> types.Type written relative to target package, like *models.User
+var renderFuncs = template.FuncMap{
+	"import":  func(string, ...string) (string, error) { return "", nil },
+	"ref":     func(string, string) (string, error) { return "", nil },
+	"qualify": func(types.Type) string { return "" },
+}
+
+// fileFuncs returns render funcs bound to generated file.
+func (gd *cmdData) fileFuncs(packageName func(path string) string) template.FuncMap {
+	return template.FuncMap{
+		"import": func(importPath string, alias ...string) (string, error) {
+			if len(alias) > 1 {
+				return "", fmt.Errorf("import %q: too many aliases", importPath)
+			}
+			imp := Import{Path: importPath}
+			if len(alias) == 1 {
+				imp.Alias = alias[0]
+			}
+			return gd.addImport(imp, packageName(importPath))
+		},
+		"ref": func(importPath, name string) (string, error) {
+			pkgName, err := gd.addImport(Import{Path: importPath}, packageName(importPath))
+			if err != nil || pkgName == "" {
+				return name, err
+			}
+			return pkgName + "." + name, nil
+		},
+		"qualify": func(t types.Type) string {
+			return types.TypeString(t, gd.qualifier())
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package decls
+
+// simplegen:noop
+type Top struct{}
+
+// simplegen:noop
+const TopConst = 1
+
+// simplegen:noop
+var TopVar = 1
+
+// simplegen:noop
+func TopFunc() {
+	// simplegen:noop
+	type Local struct{}
+
+	// simplegen:noop
+	const localConst = 1
+
+	// simplegen:noop
+	var localVar = Local{}
+	_ = localVar
+}
@@ -0,0 +1,47 @@
+package fields
+
+import (
+	"time"
+
+	"github.com/AlwxSin/simplegen/testdata/fields/other"
+)
+
+type Common struct {
+	CommonID int
+	Shadowed string
+	private  bool
+}
+
+type A struct {
+	Dup   int
+	OnlyA int
+}
+
+type B struct {
+	Dup string
+}
+
+type User struct {
+	Common
+	*other.Base
+	A
+	B
+	other.Other
+	Shadowed int `json:"shadowed"`
+	// Email doc
+	Email   string `json:"email,omitempty"` // email comment
+	secret  string
+	Tags    map[string][]*other.Other
+	Created time.Time
+}
+
+type Node struct {
+	Value int
+	*Node
+}
+
+type BadTag struct {
+	Name string `json:name`
+}
+
+type NotStruct int
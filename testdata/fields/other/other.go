@@ -0,0 +1,15 @@
+package other
+
+import "time"
+
+type Base struct {
+	// ID doc
+	ID      int `json:"id"`
+	hidden  string
+	Created time.Time
+	Name    string `json:"name"`
+}
+
+type Other struct {
+	Name string
+}
@@ -0,0 +1,5 @@
+package multi
+
+// simplegen:first
+// simplegen:second
+type T struct{}
@@ -0,0 +1,6 @@
+package a
+
+import "github.com/AlwxSin/simplegen/testdata/targets/a/models"
+
+// simplegen:target
+var X models.A
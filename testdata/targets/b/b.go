@@ -0,0 +1,6 @@
+package b
+
+import "github.com/AlwxSin/simplegen/testdata/targets/b/models"
+
+// simplegen:target
+var Y models.B
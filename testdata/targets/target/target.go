@@ -0,0 +1,4 @@
+package target
+
+// T is a target package of types annotated in other packages.
+type T struct{}
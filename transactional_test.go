@@ -0,0 +1,117 @@
+package simplegen
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var errFaulty = errors.New("faulty output")
+
+// faultyOutput is a MemoryOutput which fails to stage, write or remove chosen file.
+type faultyOutput struct {
+	*MemoryOutput
+	failStage, failWrite, failRemove string
+	// writes counts successful writes
+	writes int
+}
+
+func (o *faultyOutput) StageFile(name string, content []byte) (StagedFile, error) {
+	if failsOn(name, o.failStage) {
+		return nil, errFaulty
+	}
+	return &pendingFile{output: o, name: name, content: content}, nil
+}
+
+func (o *faultyOutput) WriteFile(name string, content []byte) error {
+	// restored files are written with old content
+	if failsOn(name, o.failWrite) && isGenerated(content) && strings.Contains(string(content), "// T") {
+		return errFaulty
+	}
+	o.writes++
+	return o.MemoryOutput.WriteFile(name, content)
+}
+
+func (o *faultyOutput) RemoveFile(name string) error {
+	if failsOn(name, o.failRemove) {
+		return errFaulty
+	}
+	return o.MemoryOutput.RemoveFile(name)
+}
+
+func failsOn(name, suffix string) bool {
+	return suffix != "" && strings.HasSuffix(name, suffix)
+}
+
+func TestGenerateTransactional(t *testing.T) {
+	firstFile := testdataFile(t, "multi", "first_gen.go")
+	secondFile := testdataFile(t, "multi", "second_gen.go")
+	thirdFile := testdataFile(t, "multi", "third_gen.go")
+	old := map[string][]byte{
+		firstFile: []byte(generatedComment + "\npackage multi // first\n"),
+		thirdFile: []byte(generatedComment + "\npackage multi // third\n"),
+	}
+
+	tests := []struct {
+		name   string
+		output *faultyOutput
+		// second fails to render
+		failTemplate bool
+		wantWrites   int
+	}{
+		{name: "template fails", output: &faultyOutput{}, failTemplate: true},
+		{name: "stage fails", output: &faultyOutput{failStage: "second_gen.go"}},
+		// first file is written and restored
+		{name: "write fails", output: &faultyOutput{failWrite: "second_gen.go"}, wantWrites: 2},
+		// both files are written and restored
+		{name: "remove fails", output: &faultyOutput{failRemove: "third_gen.go"}, wantWrites: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := tt.output
+			out.MemoryOutput = NewMemoryOutput(old)
+			secondTemplate := namesTemplate
+			if tt.failTemplate {
+				secondTemplate = "{{ .Missing }}"
+			}
+			sg := newTestGenerator(t, PackageNames{"./testdata/multi"}, GeneratorsMap{
+				"first":  {Template: namesTemplate, AnnotationFunc: annotationName},
+				"second": {Template: secondTemplate, AnnotationFunc: annotationName},
+				"third":  {Template: namesTemplate, AnnotationFunc: annotationName},
+			}, WithOutput(out), WithTransactional())
+
+			err := sg.Generate()
+			if err == nil {
+				t.Fatal("Generate() = nil, want error")
+			}
+			if !tt.failTemplate && !errors.Is(err, errFaulty) {
+				t.Errorf("Generate() = %v, want output error", err)
+			}
+			if got := out.Files(); !reflect.DeepEqual(got, old) {
+				t.Errorf("output is changed:\n%q\nwant\n%q", got, old)
+			}
+			if out.writes != tt.wantWrites {
+				t.Errorf("output has %d writes, want %d", out.writes, tt.wantWrites)
+			}
+			if got := sg.Report(); len(got.Written)+len(got.Removed) > 0 {
+				t.Errorf("Report() = %+v, want nothing written or removed", got)
+			}
+		})
+	}
+
+	// without failures everything is changed
+	out := &faultyOutput{MemoryOutput: NewMemoryOutput(old)}
+	sg := newTestGenerator(t, PackageNames{"./testdata/multi"}, GeneratorsMap{
+		"first":  {Template: namesTemplate, AnnotationFunc: annotationName},
+		"second": {Template: namesTemplate, AnnotationFunc: annotationName},
+		"third":  {Template: namesTemplate, AnnotationFunc: annotationName},
+	}, WithOutput(out), WithTransactional())
+	if err := sg.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	want := Report{Written: []string{firstFile, secondFile}, Removed: []string{thirdFile}}
+	if got := sg.Report(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Report() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+package simplegen
+
+import (
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// TypeParam describes type parameter of generic type or function.
+type TypeParam struct {
+	Name string
+	// Constraint is a type parameter constraint, any or comparable for simple cases.
+	Constraint types.Type
+	// ConstraintString is a constraint written relative to target package, see SimpleGenerator.TypeString.
+	ConstraintString string
+	// Param is a type parameter itself.
+	Param *types.TypeParam
+}
+
+// TypeParams are type parameters in declaration order.
+type TypeParams []TypeParam
+
+// Names returns type parameter names.
+func (p TypeParams) Names() []string {
+	names := make([]string, 0, len(p))
+	for _, param := range p {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+// Decl returns type parameter list for declarations, "[K comparable, V any]".
+// Returns empty string if there are no type parameters.
+func (p TypeParams) Decl() string {
+	if len(p) == 0 {
+		return ""
+	}
+	params := make([]string, 0, len(p))
+	for _, param := range p {
+		params = append(params, param.Name+" "+param.ConstraintString)
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// Args returns type arguments list to instantiate type with its own parameters, "[K, V]".
+// Returns empty string if there are no type parameters.
+func (p TypeParams) Args() string {
+	if len(p) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(p.Names(), ", ") + "]"
+}
+
+// TypeParams returns type parameters of generic type or function declared by obj
+// and imports required by their constraints. Returns nil for other objects.
+//
+//	type Page[T any, S ~[]T] struct{} -> [T any, S ~[]T]
+func (sg *SimpleGenerator) TypeParams(obj types.Object, targetPkg *packages.Package) (TypeParams, []Import) {
+	var list *types.TypeParamList
+	switch t := obj.Type().(type) {
+	case *types.Named:
+		if _, ok := obj.(*types.TypeName); ok {
+			list = t.TypeParams()
+		}
+	case *types.Signature:
+		list = t.TypeParams()
+	}
+	if list == nil || list.Len() == 0 {
+		return nil, nil
+	}
+
+	var (
+		params  TypeParams
+		imports []Import
+	)
+	for i := 0; i < list.Len(); i++ {
+		param := list.At(i)
+		constraint, constraintImports := sg.TypeString(param.Constraint(), targetPkg)
+		imports = append(imports, constraintImports...)
+		params = append(params, TypeParam{
+			Name:             param.Obj().Name(),
+			Constraint:       param.Constraint(),
+			ConstraintString: constraint,
+			Param:            param,
+		})
+	}
+	return params, imports
+}
+
+// TypeParams returns type parameters of annotated generic type or function.
+// Imports required by constraints are added to generated file automatically.
+func (a *Annotation) TypeParams() TypeParams {
+	if a.Object == nil {
+		return nil
+	}
+	params, imports := a.sg.TypeParams(a.Object, a.Package)
+	a.imports = append(a.imports, imports...)
+	return params
+}
@@ -1,8 +1,9 @@
 package simplegen
 
 import (
-	"errors"
 	"go/ast"
+	"go/types"
+	"sort"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -10,12 +11,6 @@ import (
 
 const CmdKey = "simplegen"
 
-type sgErrors []error
-
-func (e sgErrors) Error() string {
-	return errors.Join(e...).Error()
-}
-
 type pkgPath string
 
 type GeneratorName string
@@ -33,11 +28,35 @@ type GeneratorFunc func(
 	comment *ast.Comment,
 ) (templateData SpecData, imports []string, err error)
 
-// TemplateGenerator contains raw template and GeneratorFunc to generate template data.
+// TemplateGenerator contains raw template and generator func to generate template data.
+// One of AnnotationFunc or GeneratorFunc should be set.
 type TemplateGenerator struct {
 	// Template is a string which contains full template in go style
-	Template      string
+	Template string
+	// AnnotationFunc is called for any annotated declaration.
+	// Takes precedence over GeneratorFunc.
+	AnnotationFunc AnnotationFunc
+	// GeneratorFunc is called for annotated types only.
 	GeneratorFunc GeneratorFunc
+
+	// Args describes arguments of magic comment.
+	// If set, SimpleGenerator parses and validates arguments before calling generator func,
+	// use Annotation.Args or SimpleGenerator.Args to get them.
+	Args []Arg
+	// Usage is a help text for generator.
+	Usage string
+}
+
+// annotationFunc returns generator func to call, GeneratorFunc is adapted to AnnotationFunc.
+func (tg TemplateGenerator) annotationFunc() AnnotationFunc {
+	switch {
+	case tg.AnnotationFunc != nil:
+		return tg.AnnotationFunc
+	case tg.GeneratorFunc != nil:
+		return tg.GeneratorFunc.Annotated()
+	default:
+		return nil
+	}
 }
 
 // GeneratorsMap cmd_name -> func_to_generate_template_data
@@ -54,21 +73,108 @@ type SpecData any
 // cmdData internal struct. Will pass it to template.
 type cmdData struct {
 	PackageName string
-	Imports     []string
+	// Imports contains imports of all specs sorted, standard library goes first.
+	Imports []Import
+	// ImportGroups contains Imports split into standard library and other imports.
+	ImportGroups [][]Import
 
 	Specs []SpecData
+
+	// annotations are origins of Specs
+	annotations []*Annotation
+	imports     map[Import]struct{}
+	// names maps import path to package name used in file
+	names map[string]string
+	table *importTable
 }
 
-func newGeneratorData(pkgName string, imports []string) *cmdData {
+func newGeneratorData(pkgName string, table *importTable) *cmdData {
 	return &cmdData{
 		PackageName: pkgName,
-		Imports:     imports,
 		Specs:       make([]SpecData, 0),
+		imports:     make(map[Import]struct{}),
+		names:       make(map[string]string),
+		table:       table,
 	}
 }
 
-func (gd *cmdData) add(sd SpecData) {
+func (gd *cmdData) add(sd SpecData, a *Annotation) {
 	gd.Specs = append(gd.Specs, sd)
+	gd.annotations = append(gd.annotations, a)
+}
+
+// single returns file data with the only spec to execute template for it separately.
+// Import table is cloned, so names assigned to file imports don't change.
+func (gd *cmdData) single(i int) *cmdData {
+	data := newGeneratorData(gd.PackageName, gd.table.clone())
+	data.add(gd.Specs[i], gd.annotations[i])
+	return data
+}
+
+// addImport resolves name of imported package and adds it to file imports.
+// pkgName is a real package name, empty if unknown.
+// Returns empty name for import of target package itself.
+func (gd *cmdData) addImport(rawImp Import, pkgName string) (string, error) {
+	imp, name, err := gd.table.fileImport(rawImp, pkgName)
+	if err != nil || name == "" {
+		return name, err
+	}
+
+	gd.imports[imp] = struct{}{}
+	if _, ok := gd.names[rawImp.Path]; !ok && rawImp.Alias == "" {
+		gd.names[rawImp.Path] = name
+	}
+	return name, nil
+}
+
+// qualifier writes package names relative to target package and adds packages to file imports.
+func (gd *cmdData) qualifier() types.Qualifier {
+	return gd.table.qualifier(func(imp Import) {
+		gd.imports[imp] = struct{}{}
+	})
+}
+
+// ImportName returns name of imported package to use in generated file.
+// Can be used in templates: {{ $.ImportName "github.com/my_project/models" }}.
+func (gd *cmdData) ImportName(importPath string) string {
+	if name, ok := gd.names[importPath]; ok {
+		return name
+	}
+	if name, ok := gd.table.defaultName(importPath); ok {
+		return name
+	}
+	return guessPackageName(importPath)
+}
+
+// groupImports sorts collected imports and groups them, standard library goes first.
+func (gd *cmdData) groupImports(isStdLib func(path string) bool) {
+	var std, other []Import
+	for imp := range gd.imports {
+		if isStdLib(imp.Path) {
+			std = append(std, imp)
+		} else {
+			other = append(other, imp)
+		}
+	}
+	sortImports(std)
+	sortImports(other)
+
+	gd.Imports = append(std, other...)
+	gd.ImportGroups = nil
+	for _, group := range [][]Import{std, other} {
+		if len(group) > 0 {
+			gd.ImportGroups = append(gd.ImportGroups, group)
+		}
+	}
+}
+
+func sortImports(imports []Import) {
+	sort.Slice(imports, func(i, j int) bool {
+		if imports[i].Path != imports[j].Path {
+			return imports[i].Path < imports[j].Path
+		}
+		return imports[i].Alias < imports[j].Alias
+	})
 }
 
 // PackageNames is a helper for flag.Parse
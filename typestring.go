@@ -0,0 +1,32 @@
+package simplegen
+
+import (
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// TypeString writes type relative to target package, like types.TypeString, and returns imports it requires.
+// Package names follow import names of target package, so aliases assigned to colliding imports are respected.
+// For other target than annotated package, generator funcs of each annotated package have own import names.
+// Works with every kind of type: named, pointers, slices, arrays, maps, channels, funcs,
+// anonymous structs and interfaces, instantiated generics.
+// Call it from generator funcs, import names are assigned during generation.
+//
+//	[]*models.User, [{Path: "github.com/my_project/models"}]
+//	map[string]chan<- models2.Event, [{Path: "github.com/other/models", Alias: "models2"}]
+func (sg *SimpleGenerator) TypeString(t types.Type, targetPkg *packages.Package) (string, []Import) {
+	var imports []Import
+	qualifier := sg.targetTable(targetPkg).qualifier(func(imp Import) {
+		imports = append(imports, imp)
+	})
+	return types.TypeString(t, qualifier), imports
+}
+
+// TypeString writes type relative to annotated package.
+// Required imports are added to generated file automatically.
+func (a *Annotation) TypeString(t types.Type) string {
+	s, imports := a.sg.TypeString(t, a.Package)
+	a.imports = append(a.imports, imports...)
+	return s
+}